@@ -0,0 +1,98 @@
+package docrouter
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// sensitiveExtension marks parameters tagged with `sensitive: true`,
+// their values are redacted in access logs.
+const sensitiveExtension = "x-sensitive"
+
+// Redacted replaces values of sensitive parameters in access logs.
+const Redacted = "[REDACTED]"
+
+// AccessLogger writes access log entries, see NewSlogAccessLogger.
+type AccessLogger interface {
+	LogAccess(ctx context.Context, entry AccessLogEntry)
+}
+
+// AccessLoggerFunc is an adapter to use ordinary functions as AccessLogger.
+type AccessLoggerFunc func(ctx context.Context, entry AccessLogEntry)
+
+func (f AccessLoggerFunc) LogAccess(ctx context.Context, entry AccessLogEntry) {
+	f(ctx, entry)
+}
+
+// AccessLogEntry describes a request served by a route.
+type AccessLogEntry struct {
+	OperationID  string
+	Tags         []string
+	Method       string
+	PathTemplate string
+	// Params are documented parameters sent in the request in the documented order.
+	// Values of sensitive parameters are Redacted.
+	Params       []AccessLogParam
+	Status       int
+	BytesWritten int64
+	Duration     time.Duration
+}
+
+type AccessLogParam struct {
+	In    string
+	Name  string
+	Value string
+}
+
+func (srv *Router) accessLogMiddleware(route *Route, pathTemplate string, params openapi3.Parameters) func(http.Handler) http.Handler {
+	logger := srv.opts.AccessLog
+	operationID := uniqueOperationID(route)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			sw := &statusWriter{ResponseWriter: w}
+			// panics not recovered by Options.Recover are logged with 500
+			completed := false
+			defer func() {
+				status := sw.statusCode()
+				if !completed {
+					status = http.StatusInternalServerError
+				}
+				logger.LogAccess(r.Context(), AccessLogEntry{
+					OperationID:  operationID,
+					Tags:         route.Tags,
+					Method:       r.Method,
+					PathTemplate: pathTemplate,
+					Params:       accessLogParams(r, params),
+					Status:       status,
+					BytesWritten: sw.written,
+					Duration:     time.Since(start),
+				})
+			}()
+			next.ServeHTTP(sw, r)
+			completed = true
+		})
+	}
+}
+
+func accessLogParams(r *http.Request, params openapi3.Parameters) []AccessLogParam {
+	logged := []AccessLogParam{}
+	for _, ref := range params {
+		param := ref.Value
+		if param == nil || !paramSent(r, param) {
+			continue
+		}
+		value, err := strValueFromRequest(param.Name, param.In, r)
+		if err != nil {
+			continue
+		}
+		if sensitive, _ := param.Extensions[sensitiveExtension].(bool); sensitive {
+			value = Redacted
+		}
+		logged = append(logged, AccessLogParam{In: param.In, Name: param.Name, Value: value})
+	}
+	return logged
+}
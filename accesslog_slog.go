@@ -0,0 +1,34 @@
+//go:build go1.21
+
+package docrouter
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+)
+
+// NewSlogAccessLogger returns AccessLogger writing entries to the logger.
+// Requests answered with 5xx status are logged as errors.
+func NewSlogAccessLogger(logger *slog.Logger) AccessLogger {
+	return AccessLoggerFunc(func(ctx context.Context, entry AccessLogEntry) {
+		level := slog.LevelInfo
+		if entry.Status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		params := make([]any, 0, len(entry.Params))
+		for _, p := range entry.Params {
+			params = append(params, slog.String(p.In+"."+p.Name, p.Value))
+		}
+		logger.LogAttrs(ctx, level, "access",
+			slog.String("operation_id", entry.OperationID),
+			slog.Any("tags", entry.Tags),
+			slog.String("method", entry.Method),
+			slog.String("route", entry.PathTemplate),
+			slog.Group("params", params...),
+			slog.Int("status", entry.Status),
+			slog.Int64("bytes", entry.BytesWritten),
+			slog.Duration("duration", entry.Duration),
+		)
+	})
+}
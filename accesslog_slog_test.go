@@ -0,0 +1,46 @@
+//go:build go1.21
+
+package docrouter
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlogAccessLogger(t *testing.T) {
+	var buf bytes.Buffer
+	opts := DefaultOptions
+	opts.AccessLog = NewSlogAccessLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+	router := New(opts)
+
+	type params struct {
+		Token string `docrouter:"name: token; kind: query; sensitive: true"`
+	}
+	require.NoError(t, router.AddRoute(Route{
+		Path:        "/stars",
+		Methods:     []string{http.MethodGet},
+		Parameters:  &params{},
+		Summary:     "List stars",
+		OperationID: "listStars",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "boom", http.StatusInternalServerError)
+		}),
+	}))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/stars?token=abc", nil))
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "ERROR", record["level"])
+	assert.Equal(t, "listStars", record["operation_id"])
+	assert.Equal(t, "/stars", record["route"])
+	assert.Equal(t, float64(500), record["status"])
+	assert.Equal(t, map[string]interface{}{"query.token": Redacted}, record["params"])
+	assert.NotContains(t, buf.String(), "abc")
+}
@@ -0,0 +1,104 @@
+package docrouter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLog(t *testing.T) {
+	entries := []AccessLogEntry{}
+	opts := DefaultOptions
+	opts.AccessLog = AccessLoggerFunc(func(ctx context.Context, entry AccessLogEntry) {
+		entries = append(entries, entry)
+	})
+	router := New(opts)
+
+	type params struct {
+		StarID int    `docrouter:"name: starId; kind: path"`
+		Token  string `docrouter:"name: X-Token; kind: header; sensitive: true"`
+		Secret string `docrouter:"name: secret; kind: query; sensitive: true"`
+		Limit  int    `docrouter:"name: limit; kind: query"`
+	}
+	require.NoError(t, router.AddRoute(Route{
+		Path:        "/stars/{starId}",
+		Methods:     []string{http.MethodGet},
+		Parameters:  &params{},
+		Summary:     "Get star",
+		OperationID: "getStar",
+		Tags:        []string{"stars"},
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+			_, _ = w.Write([]byte("star"))
+		}),
+	}))
+
+	doc := router.OpenAPI()
+	operation := doc.Paths["/stars/{starId}"].Get
+	assert.Equal(t, true, operation.Parameters.GetByInAndName("header", "X-Token").Extensions[sensitiveExtension])
+	assert.Nil(t, operation.Parameters.GetByInAndName("query", "limit").Extensions[sensitiveExtension])
+
+	req := httptest.NewRequest(http.MethodGet, "/stars/1?secret=s3cr3t&limit=5", nil)
+	req.Header.Set("X-Token", "t0ken")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Len(t, entries, 1)
+	entry := entries[0]
+	assert.Equal(t, "getStar", entry.OperationID)
+	assert.Equal(t, []string{"stars"}, entry.Tags)
+	assert.Equal(t, http.MethodGet, entry.Method)
+	assert.Equal(t, "/stars/{starId}", entry.PathTemplate)
+	assert.Equal(t, http.StatusTeapot, entry.Status)
+	assert.Equal(t, int64(4), entry.BytesWritten)
+	assert.ElementsMatch(t, []AccessLogParam{
+		{In: "path", Name: "starId", Value: "1"},
+		{In: "header", Name: "X-Token", Value: Redacted},
+		{In: "query", Name: "secret", Value: Redacted},
+		{In: "query", Name: "limit", Value: "5"},
+	}, entry.Params)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/stars/2", nil))
+	require.Len(t, entries, 2)
+	assert.Equal(t, []AccessLogParam{{In: "path", Name: "starId", Value: "2"}}, entries[1].Params, "params not sent aren't logged")
+}
+
+func TestSensitiveTagInvalid(t *testing.T) {
+	type params struct {
+		Token string `docrouter:"name: token; kind: query; sensitive: maybe"`
+	}
+	router := New(DefaultOptions)
+	err := router.AddRoute(Route{
+		Path:       "/stars",
+		Methods:    []string{http.MethodGet},
+		Parameters: &params{},
+		Handler:    http.NotFoundHandler(),
+	})
+	assert.Error(t, err)
+}
+
+func TestAccessLogPanic(t *testing.T) {
+	var entries []AccessLogEntry
+	opts := DefaultOptions
+	opts.AccessLog = AccessLoggerFunc(func(ctx context.Context, entry AccessLogEntry) {
+		entries = append(entries, entry)
+	})
+	router := New(opts)
+	require.NoError(t, router.AddRoute(Route{
+		Path:    "/boom",
+		Methods: []string{http.MethodGet},
+		Summary: "Boom",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}),
+	}))
+
+	assert.Panics(t, func() {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/boom", nil))
+	})
+	require.Len(t, entries, 1)
+	assert.Equal(t, http.StatusInternalServerError, entries[0].Status)
+}
@@ -0,0 +1,235 @@
+package docrouter
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Cache makes responses of GET and HEAD requests cacheable and answers
+// conditional requests with 304 Not Modified. The conditional request
+// headers and the 304 response are documented.
+type Cache struct {
+	// Control is Cache-Control header of successful responses, e.g. "public, max-age=60".
+	Control string
+	// ETag sets ETag header to hash of the response body. Responses are buffered,
+	// so the hash can be compared with If-None-Match request header.
+	ETag bool
+	// LastModified returns when the requested resource was modified, zero time when unknown.
+	// Requests with If-Modified-Since header are answered without calling the handler
+	// when the resource wasn't modified since.
+	LastModified func(r *http.Request) time.Time
+}
+
+func cacheableMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+func (c *Cache) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !cacheableMethod(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if c.LastModified != nil {
+			if modified := c.LastModified(r); !modified.IsZero() {
+				w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+				if r.Header.Get("If-None-Match") == "" && notModifiedSince(r, modified) {
+					c.writeNotModified(w)
+					return
+				}
+			}
+		}
+		cw := &cacheWriter{ResponseWriter: w, cache: c}
+		next.ServeHTTP(cw, r)
+		if cw.hijacked {
+			return
+		}
+		if cw.status == 0 {
+			cw.WriteHeader(http.StatusOK)
+		}
+		if !c.ETag {
+			return
+		}
+		if cw.status != http.StatusOK {
+			cw.writeBuffered()
+			return
+		}
+		etag := bodyETag(cw.body.Bytes())
+		w.Header().Set("ETag", etag)
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			c.writeNotModified(w)
+			return
+		}
+		cw.writeBuffered()
+	})
+}
+
+func (c *Cache) writeNotModified(w http.ResponseWriter) {
+	if c.Control != "" {
+		w.Header().Set("Cache-Control", c.Control)
+	}
+	w.WriteHeader(http.StatusNotModified)
+}
+
+func notModifiedSince(r *http.Request, modified time.Time) bool {
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !modified.Truncate(time.Second).After(since)
+}
+
+func bodyETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches compares ETags of If-None-Match header with weak comparison.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheWriter sets Cache-Control header of successful responses,
+// the response is buffered when ETag is computed.
+type cacheWriter struct {
+	http.ResponseWriter
+	cache    *Cache
+	status   int
+	body     bytes.Buffer
+	hijacked bool
+}
+
+func (cw *cacheWriter) WriteHeader(status int) {
+	if cw.status != 0 {
+		return
+	}
+	cw.status = status
+	if status == http.StatusOK && cw.cache.Control != "" && cw.Header().Get("Cache-Control") == "" {
+		cw.Header().Set("Cache-Control", cw.cache.Control)
+	}
+	if !cw.cache.ETag {
+		cw.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (cw *cacheWriter) Write(b []byte) (int, error) {
+	if cw.status == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.cache.ETag {
+		return cw.body.Write(b)
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+func (cw *cacheWriter) writeBuffered() {
+	cw.ResponseWriter.WriteHeader(cw.status)
+	_, _ = cw.ResponseWriter.Write(cw.body.Bytes())
+}
+
+// Flush sends the response unless it's buffered.
+func (cw *cacheWriter) Flush() {
+	if cw.cache.ETag {
+		return
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack is used e.g. by websocket upgrades, the response isn't cached then.
+func (cw *cacheWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := hijack(cw.ResponseWriter)
+	if err == nil {
+		cw.hijacked = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap is used by http.ResponseController.
+func (cw *cacheWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// documentCache adds conditional request headers and 304 response to GET or HEAD operation.
+func (c *Cache) documentCache(operation *openapi3.Operation) {
+	params := append(openapi3.Parameters{}, operation.Parameters...)
+	if c.ETag && params.GetByInAndName(openapi3.ParameterInHeader, "If-None-Match") == nil {
+		params = append(params, &openapi3.ParameterRef{
+			Value: openapi3.NewHeaderParameter("If-None-Match").
+				WithDescription("ETag of the cached response, 304 is returned when it matches").
+				WithSchema(openapi3.NewStringSchema()),
+		})
+	}
+	if c.LastModified != nil && params.GetByInAndName(openapi3.ParameterInHeader, "If-Modified-Since") == nil {
+		params = append(params, &openapi3.ParameterRef{
+			Value: openapi3.NewHeaderParameter("If-Modified-Since").
+				WithDescription("Time of the cached response, 304 is returned when the resource wasn't modified since").
+				WithSchema(openapi3.NewStringSchema()),
+		})
+	}
+	operation.Parameters = params
+
+	headers := c.responseHeaders()
+	responses := openapi3.Responses{}
+	for status, response := range operation.Responses {
+		responses[status] = response
+	}
+	if success := responses["200"]; success != nil && success.Ref == "" && success.Value != nil {
+		// the inline response is shared by operations of the route
+		value := *success.Value
+		value.Headers = openapi3.Headers{}
+		for name, header := range success.Value.Headers {
+			value.Headers[name] = header
+		}
+		for name, header := range headers {
+			value.Headers[name] = header
+		}
+		responses["200"] = &openapi3.ResponseRef{Value: &value}
+	}
+	if _, found := responses["304"]; !found {
+		notModified := openapi3.NewResponse().WithDescription("Not modified")
+		notModified.Headers = headers
+		responses["304"] = &openapi3.ResponseRef{Value: notModified}
+	}
+	operation.Responses = responses
+}
+
+func (c *Cache) responseHeaders() openapi3.Headers {
+	headers := openapi3.Headers{}
+	if c.Control != "" {
+		headers["Cache-Control"] = stringHeader("Caching directives")
+	}
+	if c.ETag {
+		headers["ETag"] = stringHeader("Hash of the response body")
+	}
+	if c.LastModified != nil {
+		headers["Last-Modified"] = stringHeader("Time the resource was modified")
+	}
+	return headers
+}
+
+func stringHeader(description string) *openapi3.HeaderRef {
+	return &openapi3.HeaderRef{
+		Value: &openapi3.Header{
+			Parameter: openapi3.Parameter{
+				Description: description,
+				Schema:      openapi3.NewStringSchema().NewRef(),
+			},
+		},
+	}
+}
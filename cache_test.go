@@ -0,0 +1,148 @@
+package docrouter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheETag(t *testing.T) {
+	calls := 0
+	router := New(DefaultOptions)
+	require.NoError(t, router.AddRoute(Route{
+		Path:         "/stars",
+		Methods:      []string{http.MethodGet},
+		Summary:      "List stars",
+		ResponseBody: []string{},
+		Cache:        &Cache{Control: "public, max-age=60", ETag: true},
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			_, _ = w.Write([]byte(`["sun"]`))
+		}),
+	}))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stars", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `["sun"]`, w.Body.String())
+	assert.Equal(t, "public, max-age=60", w.Header().Get("Cache-Control"))
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	req := httptest.NewRequest(http.MethodGet, "/stars", nil)
+	req.Header.Set("If-None-Match", `"other", W/`+etag)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+	assert.Equal(t, "public, max-age=60", w.Header().Get("Cache-Control"))
+
+	req = httptest.NewRequest(http.MethodGet, "/stars", nil)
+	req.Header.Set("If-None-Match", `"other"`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 3, calls)
+
+	operation := router.OpenAPI().Paths["/stars"].Get
+	assert.NotNil(t, operation.Parameters.GetByInAndName("header", "If-None-Match"))
+	assert.Nil(t, operation.Parameters.GetByInAndName("header", "If-Modified-Since"))
+	require.NotNil(t, operation.Responses.Get(http.StatusNotModified))
+	assert.Contains(t, operation.Responses.Get(http.StatusOK).Value.Headers, "ETag")
+	require.NoError(t, router.Validate(context.Background()))
+}
+
+func TestCacheLastModified(t *testing.T) {
+	modified := time.Date(2020, 1, 2, 3, 4, 5, 600, time.UTC)
+	calls := 0
+	cache := &Cache{
+		Control:      "no-cache",
+		LastModified: func(r *http.Request) time.Time { return modified },
+	}
+	router := New(DefaultOptions)
+	require.NoError(t, router.AddRoute(Route{
+		Path:    "/stars",
+		Methods: []string{http.MethodGet},
+		Summary: "List stars",
+		Cache:   cache,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+		}),
+	}))
+	require.NoError(t, router.AddRoute(Route{
+		Path:    "/stars",
+		Methods: []string{http.MethodPost},
+		Summary: "Create star",
+		Cache:   cache,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+		}),
+	}))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stars", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Thu, 02 Jan 2020 03:04:05 GMT", w.Header().Get("Last-Modified"))
+	assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+
+	req := httptest.NewRequest(http.MethodGet, "/stars", nil)
+	req.Header.Set("If-Modified-Since", "Thu, 02 Jan 2020 03:04:05 GMT")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, 1, calls, "handler isn't called for not modified resource")
+
+	req = httptest.NewRequest(http.MethodGet, "/stars", nil)
+	req.Header.Set("If-Modified-Since", "Thu, 02 Jan 2020 03:04:04 GMT")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/stars", nil))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Empty(t, w.Header().Get("Cache-Control"), "only GET and HEAD are cached")
+
+	doc := router.OpenAPI()
+	assert.NotNil(t, doc.Paths["/stars"].Get.Parameters.GetByInAndName("header", "If-Modified-Since"))
+	assert.NotNil(t, doc.Paths["/stars"].Get.Responses.Get(http.StatusNotModified))
+	assert.Nil(t, doc.Paths["/stars"].Post.Parameters.GetByInAndName("header", "If-Modified-Since"))
+	assert.Nil(t, doc.Paths["/stars"].Post.Responses.Get(http.StatusNotModified))
+	require.NoError(t, router.Validate(context.Background()))
+}
+
+func TestCacheHijack(t *testing.T) {
+	router := New(DefaultOptions)
+	require.NoError(t, router.AddRoute(Route{
+		Path:    "/socket",
+		Methods: []string{http.MethodGet},
+		Summary: "Socket",
+		Cache:   &Cache{ETag: true},
+		Handler: switchingProtocolsHandler,
+	}))
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/socket")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+}
+
+// switchingProtocolsHandler hijacks the connection as websocket upgrades do.
+var switchingProtocolsHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	conn, rw, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer conn.Close()
+	_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: star\r\nConnection: Upgrade\r\n\r\n")
+	_ = rw.Flush()
+})
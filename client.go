@@ -0,0 +1,123 @@
+package docrouter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ClientError is returned by DecodeClientResponse for non-2xx responses.
+type ClientError struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *ClientError) Error() string {
+	return fmt.Sprintf("unexpected status code %d: %s", e.StatusCode, strings.TrimSpace(string(e.Body)))
+}
+
+// NewClientRequest creates request for the route with given path template.
+// It's a runtime helper for clients generated by docrouter-gen.
+//
+// The params is a parameters struct pointer as used with DecodeParams, path and query
+// parameters are encoded in URL, header and cookie parameters are set on the request.
+// Non-nil body is encoded as JSON.
+func NewClientRequest(ctx context.Context, method, baseURL, pathTemplate string, params, body interface{}) (*http.Request, error) {
+	routeURL, err := BuildURL(pathTemplate, params)
+	if err != nil {
+		return nil, fmt.Errorf("build url: %w", err)
+	}
+	base, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("parse base url: %v", err)
+	}
+	base.Path += routeURL.Path
+	base.RawQuery = routeURL.RawQuery
+
+	var bodyReader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("encode body: %v", err)
+		}
+		bodyReader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, base.String(), bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("new request: %v", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if err := setClientRequestParams(req, params); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func setClientRequestParams(req *http.Request, params interface{}) error {
+	if params == nil {
+		return nil
+	}
+	pParam, err := parseParameter(params)
+	if err != nil {
+		return fmt.Errorf("parsing param: %w", err)
+	}
+	sElem := reflect.ValueOf(params).Elem()
+	for _, tField := range pParam.fields {
+		paramName, paramKind := tField.getTagName(), tField.getTagKind()
+		if paramName == "" {
+			continue
+		}
+		if paramKind != openapi3.ParameterInHeader && paramKind != openapi3.ParameterInCookie {
+			continue
+		}
+		fieldVal := sElem.FieldByName(tField.name)
+		if fieldVal.IsZero() {
+			continue
+		}
+		valueStr, err := strValueFromStructField(fieldVal)
+		if err != nil {
+			return fmt.Errorf("field %q: %w", tField.name, err)
+		}
+		if paramKind == openapi3.ParameterInHeader {
+			req.Header.Set(paramName, valueStr)
+		} else {
+			req.AddCookie(&http.Cookie{Name: paramName, Value: valueStr})
+		}
+	}
+	return nil
+}
+
+// DecodeClientResponse reads and closes the response body.
+// It's a runtime helper for clients generated by docrouter-gen.
+//
+// Non-2xx responses are returned as *ClientError. Otherwise the JSON body
+// is decoded into out unless out is nil or the body is empty.
+func DecodeClientResponse(resp *http.Response, out interface{}) error {
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read body: %v", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &ClientError{StatusCode: resp.StatusCode, Body: body}
+	}
+	if out == nil || len(body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("decode body: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package docrouter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientRequest(t *testing.T) {
+	type StarParameters struct {
+		StarID  int    `docrouter:"name:starId; kind:path"`
+		Verbose bool   `docrouter:"name:verbose; kind:query"`
+		Color   string `docrouter:"name:Color; kind:header"`
+		Session string `docrouter:"name:session; kind:cookie"`
+	}
+	type Star struct {
+		Name string `json:"name"`
+	}
+
+	router := New(DefaultOptions)
+	require.NoError(t, router.AddRoute(Route{
+		Path:         "/stars/{starId}",
+		Methods:      []string{http.MethodPut},
+		Parameters:   &StarParameters{},
+		RequestBody:  &Star{},
+		ResponseBody: &Star{},
+		Summary:      "Replace star",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var params StarParameters
+			if err := DecodeParams(&params, r); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			var star Star
+			if err := json.NewDecoder(r.Body).Decode(&star); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			assert.Equal(t, StarParameters{StarID: 7, Verbose: true, Color: "red", Session: "abc"}, params)
+			star.Name += "!"
+			json.NewEncoder(w).Encode(star)
+		}),
+	}))
+
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	t.Run("success", func(t *testing.T) {
+		params := &StarParameters{StarID: 7, Verbose: true, Color: "red", Session: "abc"}
+		req, err := NewClientRequest(context.Background(), http.MethodPut, ts.URL+"/", "/stars/{starId}", params, &Star{Name: "Sun"})
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		var out Star
+		require.NoError(t, DecodeClientResponse(resp, &out))
+		assert.Equal(t, "Sun!", out.Name)
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		req, err := NewClientRequest(context.Background(), http.MethodPut, ts.URL, "/stars/{starId}", &StarParameters{StarID: 7}, nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		err = DecodeClientResponse(resp, nil)
+		var clientErr *ClientError
+		require.ErrorAs(t, err, &clientErr)
+		assert.Equal(t, http.StatusBadRequest, clientErr.StatusCode)
+	})
+}
@@ -0,0 +1,367 @@
+// Package clientgen generates typed Go HTTP clients from routes registered on docrouter.Router.
+//
+// Generated clients reuse Go types of route parameters and bodies, so they need
+// the types to be exported from an importable package.
+package clientgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/zdebra/docrouter"
+)
+
+// Config configures generated client.
+type Config struct {
+	// PackageName of the generated file. Defaults to "client".
+	PackageName string
+	// ClientName is the name of generated client type. Defaults to "Client".
+	ClientName string
+}
+
+// Generate writes source of a typed client for all the router routes to w.
+//
+// Each route method becomes a client method named after the operation ID.
+// Routes with multiple methods get the HTTP method appended to the name.
+func Generate(w io.Writer, router *docrouter.Router, cfg Config) error {
+	if cfg.PackageName == "" {
+		cfg.PackageName = "client"
+	}
+	if cfg.ClientName == "" {
+		cfg.ClientName = "Client"
+	}
+
+	g := generator{
+		imports: map[string]string{},
+		aliases: map[string]bool{
+			"context":   true,
+			"http":      true,
+			"docrouter": true,
+		},
+	}
+	data := templateData{
+		PackageName: cfg.PackageName,
+		ClientName:  cfg.ClientName,
+	}
+	names := map[string]string{}
+	err := router.Walk(func(route docrouter.RouteInfo) error {
+		for _, method := range route.Methods {
+			op, err := g.operation(route, method)
+			if err != nil {
+				return fmt.Errorf("operation %q %s: %w", route.OperationID, method, err)
+			}
+			if prev, found := names[op.Name]; found {
+				return fmt.Errorf("method name %q of operation %q collides with operation %q", op.Name, route.OperationID, prev)
+			}
+			names[op.Name] = route.OperationID
+			data.Operations = append(data.Operations, op)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for pkgPath, alias := range g.imports {
+		data.Imports = append(data.Imports, importSpec{Alias: alias, Path: pkgPath})
+	}
+	sort.Slice(data.Imports, func(i, j int) bool {
+		return data.Imports[i].Path < data.Imports[j].Path
+	})
+
+	var buf bytes.Buffer
+	if err := clientTemplate.Execute(&buf, data); err != nil {
+		return fmt.Errorf("execute template: %v", err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("format source: %v", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+type templateData struct {
+	PackageName string
+	ClientName  string
+	Imports     []importSpec
+	Operations  []operation
+}
+
+type importSpec struct {
+	Alias string
+	Path  string
+}
+
+type operation struct {
+	Name         string
+	Summary      string
+	HTTPMethod   string
+	Method       string
+	PathTemplate string
+	ParamsType   string
+	BodyType     string
+	// ResponseType is the type returned by the method, ResponseElem is allocated for decoding
+	ResponseType string
+	ResponseElem string
+	// ResponseRef is true when address of decoded ResponseElem is returned
+	ResponseRef bool
+}
+
+type generator struct {
+	imports map[string]string // package path -> alias
+	aliases map[string]bool
+}
+
+func (g *generator) operation(route docrouter.RouteInfo, method string) (operation, error) {
+	name := goName(route.OperationID)
+	if len(route.Methods) > 1 {
+		name += goName(strings.ToLower(method))
+	}
+	if name == "" {
+		return operation{}, fmt.Errorf("can't derive method name")
+	}
+	op := operation{
+		Name:         name,
+		Summary:      route.Summary,
+		HTTPMethod:   method,
+		Method:       httpMethodExpr(method),
+		PathTemplate: route.ServedPath,
+	}
+
+	var err error
+	if route.ParametersType != nil {
+		if op.ParamsType, err = g.argType(route.ParametersType); err != nil {
+			return op, fmt.Errorf("parameters: %w", err)
+		}
+	}
+	if route.RequestBodyType != nil {
+		if op.BodyType, err = g.argType(route.RequestBodyType); err != nil {
+			return op, fmt.Errorf("request body: %w", err)
+		}
+	}
+	if route.ResponseBodyType != nil {
+		t := route.ResponseBodyType
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if op.ResponseElem, err = g.typeExpr(t); err != nil {
+			return op, fmt.Errorf("response body: %w", err)
+		}
+		op.ResponseType = op.ResponseElem
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Map {
+			op.ResponseType = "*" + op.ResponseElem
+			op.ResponseRef = true
+		}
+	}
+	return op, nil
+}
+
+// argType returns type expression for method argument. Structs are passed by pointer.
+func (g *generator) argType(t reflect.Type) (string, error) {
+	if t.Kind() == reflect.Struct {
+		t = reflect.PtrTo(t)
+	}
+	return g.typeExpr(t)
+}
+
+func (g *generator) typeExpr(t reflect.Type) (string, error) {
+	if t.Name() != "" {
+		if t.PkgPath() == "" {
+			return t.Name(), nil
+		}
+		if t.PkgPath() == "main" {
+			return "", fmt.Errorf("type %s is declared in package main and can't be imported", t)
+		}
+		if !isExported(t.Name()) {
+			return "", fmt.Errorf("type %s is not exported", t)
+		}
+		return g.importAlias(t.PkgPath()) + "." + t.Name(), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		elem, err := g.typeExpr(t.Elem())
+		return "*" + elem, err
+	case reflect.Slice:
+		elem, err := g.typeExpr(t.Elem())
+		return "[]" + elem, err
+	case reflect.Array:
+		elem, err := g.typeExpr(t.Elem())
+		return fmt.Sprintf("[%d]%s", t.Len(), elem), err
+	case reflect.Map:
+		key, err := g.typeExpr(t.Key())
+		if err != nil {
+			return "", err
+		}
+		elem, err := g.typeExpr(t.Elem())
+		return "map[" + key + "]" + elem, err
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "interface{}", nil
+		}
+	}
+	return "", fmt.Errorf("unnamed type %s is not supported, declare a named type", t)
+}
+
+var docrouterPkgPath = reflect.TypeOf(docrouter.Router{}).PkgPath()
+
+func (g *generator) importAlias(pkgPath string) string {
+	if pkgPath == docrouterPkgPath {
+		return "docrouter"
+	}
+	if alias, found := g.imports[pkgPath]; found {
+		return alias
+	}
+	base := goIdent(path.Base(pkgPath))
+	alias := base
+	for i := 2; g.aliases[alias]; i++ {
+		alias = fmt.Sprintf("%s%d", base, i)
+	}
+	g.aliases[alias] = true
+	g.imports[pkgPath] = alias
+	return alias
+}
+
+// goName converts operation ID like "get-all-stars" to exported identifier "GetAllStars".
+func goName(operationID string) string {
+	var b strings.Builder
+	upperNext := true
+	for _, r := range operationID {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upperNext = true
+			continue
+		}
+		if b.Len() == 0 && unicode.IsDigit(r) {
+			b.WriteString("Op")
+		}
+		if upperNext {
+			r = unicode.ToUpper(r)
+			upperNext = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// goIdent makes valid package alias from the last import path element.
+func goIdent(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, s)
+	if s == "" || unicode.IsDigit(rune(s[0])) {
+		s = "pkg" + s
+	}
+	return s
+}
+
+func isExported(name string) bool {
+	for _, r := range name {
+		return unicode.IsUpper(r)
+	}
+	return false
+}
+
+func httpMethodExpr(method string) string {
+	switch method {
+	case http.MethodGet:
+		return "http.MethodGet"
+	case http.MethodHead:
+		return "http.MethodHead"
+	case http.MethodPost:
+		return "http.MethodPost"
+	case http.MethodPut:
+		return "http.MethodPut"
+	case http.MethodPatch:
+		return "http.MethodPatch"
+	case http.MethodDelete:
+		return "http.MethodDelete"
+	case http.MethodConnect:
+		return "http.MethodConnect"
+	case http.MethodOptions:
+		return "http.MethodOptions"
+	case http.MethodTrace:
+		return "http.MethodTrace"
+	default:
+		return fmt.Sprintf("%q", method)
+	}
+}
+
+var clientTemplate = template.Must(template.New("client").Parse(`// Code generated by docrouter-gen. DO NOT EDIT.
+
+package {{.PackageName}}
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/zdebra/docrouter"
+{{- range .Imports}}
+	{{.Alias}} "{{.Path}}"
+{{- end}}
+)
+
+// {{.ClientName}} is a typed HTTP client of the API.
+type {{.ClientName}} struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// New{{.ClientName}} creates client sending requests to baseURL with http.DefaultClient.
+func New{{.ClientName}}(baseURL string) *{{.ClientName}} {
+	return &{{.ClientName}}{
+		BaseURL:    baseURL,
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+func (c *{{.ClientName}}) do(req *http.Request, out interface{}) error {
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	return docrouter.DecodeClientResponse(resp, out)
+}
+{{range .Operations}}{{$op := .}}
+// {{.Name}} calls {{.HTTPMethod}} {{.PathTemplate}}.
+{{- if .Summary}}
+//
+// {{.Summary}}
+{{- end}}
+func (c *{{$.ClientName}}) {{.Name}}(ctx context.Context{{if .ParamsType}}, params {{.ParamsType}}{{end}}{{if .BodyType}}, body {{.BodyType}}{{end}}) ({{if .ResponseType}}{{.ResponseType}}, {{end}}error) {
+	{{- if .ParamsType}}{{else}}
+	var params interface{}
+	{{- end}}
+	{{- if .BodyType}}{{else}}
+	var body interface{}
+	{{- end}}
+	req, err := docrouter.NewClientRequest(ctx, {{.Method}}, c.BaseURL, {{printf "%q" .PathTemplate}}, params, body)
+	if err != nil {
+		return {{if .ResponseType}}nil, {{end}}err
+	}
+	{{- if .ResponseType}}
+	var out {{.ResponseElem}}
+	if err := c.do(req, &out); err != nil {
+		return nil, err
+	}
+	return {{if .ResponseRef}}&{{end}}out, nil
+	{{- else}}
+	return c.do(req, nil)
+	{{- end}}
+}
+{{end}}`))
@@ -0,0 +1,117 @@
+package clientgen
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zdebra/docrouter"
+	"github.com/zdebra/docrouter/clientgen/clientgentest"
+)
+
+func TestGenerate(t *testing.T) {
+	router := docrouter.New(docrouter.DefaultOptions)
+	noop := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	require.NoError(t, router.AddRoute(docrouter.Route{
+		Path:         "/stars/{starId}",
+		Methods:      []string{http.MethodPut, http.MethodPatch},
+		Parameters:   &clientgentest.StarParameters{},
+		RequestBody:  clientgentest.Star{},
+		ResponseBody: &clientgentest.Star{},
+		Summary:      "Star",
+		Handler:      noop,
+	}))
+	require.NoError(t, router.AddRoute(docrouter.Route{
+		Path:         "/stars",
+		Methods:      []string{http.MethodGet},
+		Parameters:   &clientgentest.ListParameters{},
+		ResponseBody: []clientgentest.Star{},
+		Summary:      "List stars",
+		Handler:      noop,
+	}))
+	require.NoError(t, router.AddRoute(docrouter.Route{
+		Path:    "/ping",
+		Methods: []string{http.MethodPost},
+		Summary: "Ping",
+		Handler: noop,
+	}))
+
+	var buf bytes.Buffer
+	require.NoError(t, Generate(&buf, router, Config{PackageName: "starsclient"}))
+	src := buf.String()
+
+	assert.Contains(t, src, "package starsclient")
+	assert.Contains(t, src, "func (c *Client) StarPut(ctx context.Context, params *clientgentest.StarParameters, body *clientgentest.Star) (*clientgentest.Star, error)")
+	assert.Contains(t, src, "func (c *Client) StarPatch(ctx context.Context, params *clientgentest.StarParameters, body *clientgentest.Star) (*clientgentest.Star, error)")
+	assert.Contains(t, src, "func (c *Client) ListStars(ctx context.Context, params *clientgentest.ListParameters) ([]clientgentest.Star, error)")
+	assert.Contains(t, src, "func (c *Client) Ping(ctx context.Context) error")
+
+	t.Run("compiles", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("builds generated code with go toolchain")
+		}
+		file := filepath.Join(t.TempDir(), "client.go")
+		require.NoError(t, ioutil.WriteFile(file, buf.Bytes(), 0o600))
+		cmd := exec.Command("go", "vet", file)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	})
+}
+
+func TestGenerateVersioned(t *testing.T) {
+	opts := docrouter.DefaultOptions
+	opts.Versioning = &docrouter.Versioning{
+		Versions: []docrouter.APIVersion{{Name: "v1"}, {Name: "v2"}},
+		Scheme:   docrouter.VersionByPath,
+	}
+	router := docrouter.New(opts)
+	require.NoError(t, router.AddRoute(docrouter.Route{
+		Path:       "/stars",
+		Methods:    []string{http.MethodGet},
+		Parameters: &clientgentest.ListParameters{},
+		Summary:    "List stars",
+		Handler:    http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	}))
+	require.NoError(t, router.AddRoute(docrouter.Route{
+		Path:     "/ping",
+		Methods:  []string{http.MethodPost},
+		Summary:  "Ping",
+		Versions: []string{"v1"},
+		Handler:  http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	}))
+
+	var buf bytes.Buffer
+	require.NoError(t, Generate(&buf, router, Config{PackageName: "starsclient"}))
+	src := buf.String()
+	assert.Contains(t, src, `"/v2/stars"`, "routes are called in their newest version")
+	assert.Contains(t, src, `"/v1/ping"`)
+}
+
+func TestGenerateErrors(t *testing.T) {
+	type localParams struct {
+		ID string `docrouter:"name:id; kind:path"`
+	}
+	router := docrouter.New(docrouter.DefaultOptions)
+	require.NoError(t, router.AddRoute(docrouter.Route{
+		Path:       "/things/{id}",
+		Methods:    []string{http.MethodGet},
+		Parameters: &localParams{},
+		Summary:    "Get thing",
+		Handler:    http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	}))
+
+	err := Generate(ioutil.Discard, router, Config{})
+	assert.Error(t, err)
+}
+
+func TestGoName(t *testing.T) {
+	assert.Equal(t, "GetAllStars", goName("get-all-stars"))
+	assert.Equal(t, "GetStar", goName("getStar"))
+	assert.Equal(t, "Op3dModel", goName("3d_model"))
+}
@@ -0,0 +1,19 @@
+// Package clientgentest provides types of routes used by clientgen tests,
+// they are in their own package, so the generated clients can import them.
+package clientgentest
+
+// Star is body of the test routes.
+type Star struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// StarParameters identify a star.
+type StarParameters struct {
+	StarID int `docrouter:"name:starId; kind:path"`
+}
+
+// ListParameters page lists of stars.
+type ListParameters struct {
+	Limit int `docrouter:"name:limit; kind:query"`
+}
@@ -0,0 +1,64 @@
+// Command docrouter-gen generates a typed Go HTTP client for routes of a docrouter.Router.
+//
+// The router is obtained by calling an exported func() *docrouter.Router
+// from the given package, so route types are the same as on the server:
+//
+//	docrouter-gen -pkg example.com/stars/api -func NewRouter -out client/client.go -package client
+//
+// It can be used with go generate:
+//
+//	//go:generate go run github.com/zdebra/docrouter/cmd/docrouter-gen -pkg example.com/stars/api -func NewRouter -out client.go
+//
+// The command must be run from a module which can resolve the package.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/zdebra/docrouter/internal/loader"
+)
+
+const generateBody = `func run(router *docrouter.Router, args []string) error {
+	return clientgen.Generate(os.Stdout, router, clientgen.Config{
+		PackageName: args[0],
+		ClientName:  args[1],
+	})
+}`
+
+func main() {
+	pkgPath := flag.String("pkg", "", "import path of the package with router constructor")
+	constructor := flag.String("func", "NewRouter", "name of func() *docrouter.Router in the package")
+	out := flag.String("out", "", "output file, standard output when empty")
+	pkgName := flag.String("package", "client", "package name of the generated client")
+	clientName := flag.String("client", "Client", "type name of the generated client")
+	flag.Parse()
+
+	if *pkgPath == "" {
+		fmt.Fprintln(os.Stderr, "docrouter-gen: -pkg is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	src, err := loader.Run(loader.Program{
+		PkgPath:     *pkgPath,
+		Constructor: *constructor,
+		Imports:     []string{"github.com/zdebra/docrouter/clientgen"},
+		Body:        generateBody,
+	}, *pkgName, *clientName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "docrouter-gen:", err)
+		os.Exit(1)
+	}
+
+	if *out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := ioutil.WriteFile(*out, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "docrouter-gen:", err)
+		os.Exit(1)
+	}
+}
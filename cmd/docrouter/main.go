@@ -0,0 +1,186 @@
+// Command docrouter works with OpenAPI documents generated by docrouter.Router.
+//
+// Usage:
+//
+//	docrouter dump -pkg example.com/stars/api [-func NewRouter] [-format json|yaml] [-swagger2] [-out file]
+//	docrouter diff [-allow-breaking] base.json revision.json
+//	docrouter lint [-warnings] spec.json
+//
+// dump calls an exported func() *docrouter.Router from the package and prints
+// its document, optionally converted to Swagger 2.0. It must be run from a module
+// which can resolve the package.
+// diff exits with status 1 when revision contains breaking changes, lint exits
+// with status 1 when the document is invalid or has lint errors.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/ghodss/yaml"
+	"github.com/zdebra/docrouter"
+	"github.com/zdebra/docrouter/internal/loader"
+	"github.com/zdebra/docrouter/specdiff"
+)
+
+const usage = `usage: docrouter <command> [flags]
+
+commands:
+  dump   print OpenAPI document of a router
+  diff   compare two OpenAPI documents and report breaking changes
+  lint   validate and lint OpenAPI document
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "dump":
+		err = dump(args)
+	case "diff":
+		err = diff(args)
+	case "lint":
+		err = lint(args)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "docrouter:", err)
+		os.Exit(1)
+	}
+}
+
+const dumpBody = `func run(router *docrouter.Router, args []string) error {
+	b, err := router.OpenAPIJSON()
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(b)
+	return err
+}`
+
+const dumpSwaggerV2Body = `func run(router *docrouter.Router, args []string) error {
+	b, losses, err := router.SwaggerV2JSON()
+	if err != nil {
+		return err
+	}
+	for _, loss := range losses {
+		fmt.Fprintln(os.Stderr, "lost in conversion:", loss)
+	}
+	_, err = os.Stdout.Write(b)
+	return err
+}`
+
+func dump(args []string) error {
+	fs := flag.NewFlagSet("dump", flag.ExitOnError)
+	pkgPath := fs.String("pkg", "", "import path of the package with router constructor")
+	constructor := fs.String("func", "NewRouter", "name of func() *docrouter.Router in the package")
+	format := fs.String("format", "json", "output format, json or yaml")
+	out := fs.String("out", "", "output file, standard output when empty")
+	swaggerV2 := fs.Bool("swagger2", false, "convert the document to Swagger 2.0, lost features are reported to standard error")
+	fs.Parse(args)
+
+	if *pkgPath == "" {
+		return fmt.Errorf("dump: -pkg is required")
+	}
+	if *format != "json" && *format != "yaml" {
+		return fmt.Errorf("dump: unknown format %q", *format)
+	}
+	body := dumpBody
+	if *swaggerV2 {
+		body = dumpSwaggerV2Body
+	}
+
+	spec, err := loader.Run(loader.Program{
+		PkgPath:     *pkgPath,
+		Constructor: *constructor,
+		Body:        body,
+	})
+	if err != nil {
+		return fmt.Errorf("dump: %w", err)
+	}
+	if *format == "yaml" {
+		if spec, err = yaml.JSONToYAML(spec); err != nil {
+			return fmt.Errorf("dump: convert to yaml: %v", err)
+		}
+	}
+
+	if *out == "" {
+		_, err = os.Stdout.Write(spec)
+		return err
+	}
+	return ioutil.WriteFile(*out, spec, 0o644)
+}
+
+func diff(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	allowBreaking := fs.Bool("allow-breaking", false, "exit with status 0 even if there are breaking changes")
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		return fmt.Errorf("diff: expected base and revision files")
+	}
+	base, err := loadSpec(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("diff: %w", err)
+	}
+	revision, err := loadSpec(fs.Arg(1))
+	if err != nil {
+		return fmt.Errorf("diff: %w", err)
+	}
+
+	changes := specdiff.Compare(base, revision)
+	for _, change := range changes {
+		fmt.Println(change)
+	}
+	if specdiff.HasBreaking(changes) && !*allowBreaking {
+		return fmt.Errorf("diff: breaking changes found")
+	}
+	return nil
+}
+
+func lint(args []string) error {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	warnings := fs.Bool("warnings", false, "exit with status 1 on warnings too")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("lint: expected spec file")
+	}
+	doc, err := loadSpec(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("lint: %w", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return fmt.Errorf("lint: invalid document: %v", err)
+	}
+
+	failed := false
+	for _, issue := range docrouter.Lint(doc) {
+		fmt.Println(issue)
+		if issue.Severity == docrouter.LintError || *warnings {
+			failed = true
+		}
+	}
+	if failed {
+		return fmt.Errorf("lint: issues found")
+	}
+	return nil
+}
+
+func loadSpec(path string) (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("load %s: %v", path, err)
+	}
+	return doc, nil
+}
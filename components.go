@@ -0,0 +1,187 @@
+package docrouter
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// namedSchema is a Go type registered with AddSchema.
+type namedSchema struct {
+	name string
+	t    reflect.Type
+}
+
+// AddSchema registers Go type of the value as a named schema component.
+// Request and response bodies reference it with $ref wherever the type is used.
+//
+// Named struct types used by several routes become components even without
+// registration, AddSchema is useful to control their names.
+func (srv *Router) AddSchema(name string, value interface{}) error {
+	if value == nil {
+		return fmt.Errorf("schema %q: value is nil", name)
+	}
+	return srv.modifyComponents(name, func(st *routerState) error {
+		return st.schemas.register(reflect.TypeOf(value), name)
+	}, func() {
+		srv.namedSchemas = append(srv.namedSchemas, namedSchema{name: name, t: reflect.TypeOf(value)})
+	})
+}
+
+// AddParameter registers a parameter component.
+// Reflected route parameters with the same location and name reference it with $ref.
+// Register parameters before adding the routes using them.
+func (srv *Router) AddParameter(name string, param *openapi3.Parameter) error {
+	return srv.addComponent(name, func(c *openapi3.Components) bool {
+		if c.Parameters == nil {
+			c.Parameters = openapi3.ParametersMap{}
+		}
+		if _, found := c.Parameters[name]; found {
+			return false
+		}
+		c.Parameters[name] = &openapi3.ParameterRef{Value: param}
+		return true
+	})
+}
+
+// AddResponse registers a response component, which can be used in Route.Responses.
+func (srv *Router) AddResponse(name string, response *openapi3.Response) error {
+	return srv.addComponent(name, func(c *openapi3.Components) bool {
+		if c.Responses == nil {
+			c.Responses = openapi3.Responses{}
+		}
+		if _, found := c.Responses[name]; found {
+			return false
+		}
+		c.Responses[name] = &openapi3.ResponseRef{Value: response}
+		return true
+	})
+}
+
+// AddHeader registers a header component.
+func (srv *Router) AddHeader(name string, header *openapi3.Header) error {
+	return srv.addComponent(name, func(c *openapi3.Components) bool {
+		if c.Headers == nil {
+			c.Headers = openapi3.Headers{}
+		}
+		if _, found := c.Headers[name]; found {
+			return false
+		}
+		c.Headers[name] = &openapi3.HeaderRef{Value: header}
+		return true
+	})
+}
+
+// AddExample registers an example component.
+func (srv *Router) AddExample(name string, example *openapi3.Example) error {
+	return srv.addComponent(name, func(c *openapi3.Components) bool {
+		if c.Examples == nil {
+			c.Examples = openapi3.Examples{}
+		}
+		if _, found := c.Examples[name]; found {
+			return false
+		}
+		c.Examples[name] = &openapi3.ExampleRef{Value: example}
+		return true
+	})
+}
+
+// addComponent adds component with add, which reports false when the name is already used.
+// Components are kept on the router so they survive Replace.
+func (srv *Router) addComponent(name string, add func(c *openapi3.Components) bool) error {
+	return srv.modifyComponents(name, func(st *routerState) error {
+		if !add(&st.docRoot.Components) {
+			return fmt.Errorf("component %q is already registered", name)
+		}
+		return nil
+	}, func() {
+		add(&srv.sharedComponents)
+	})
+}
+
+// modifyComponents applies the change to the current state and when it succeeds, it commits
+// it to the router so it's applied to states built later.
+func (srv *Router) modifyComponents(name string, apply func(st *routerState) error, commit func()) error {
+	srv.writeMu.Lock()
+	defer srv.writeMu.Unlock()
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.isFrozen() {
+		return ErrFrozen
+	}
+	if srv.specBindings != nil {
+		return fmt.Errorf("components of a router created from spec are defined by the spec")
+	}
+	if err := openapi3.ValidateIdentifier(name); err != nil {
+		return fmt.Errorf("component name: %v", err)
+	}
+	st, err := srv.writableState()
+	if err != nil {
+		return err
+	}
+	if err := apply(st); err != nil {
+		return err
+	}
+	st.validation = &serveCheck{}
+	commit()
+	srv.state.Store(st)
+	return nil
+}
+
+// applyComponents copies components registered on the router to the state.
+func (srv *Router) applyComponents(st *routerState) error {
+	c := &st.docRoot.Components
+	for name, param := range srv.sharedComponents.Parameters {
+		if c.Parameters == nil {
+			c.Parameters = openapi3.ParametersMap{}
+		}
+		c.Parameters[name] = param
+	}
+	for name, response := range srv.sharedComponents.Responses {
+		if c.Responses == nil {
+			c.Responses = openapi3.Responses{}
+		}
+		c.Responses[name] = response
+	}
+	for name, header := range srv.sharedComponents.Headers {
+		if c.Headers == nil {
+			c.Headers = openapi3.Headers{}
+		}
+		c.Headers[name] = header
+	}
+	for name, example := range srv.sharedComponents.Examples {
+		if c.Examples == nil {
+			c.Examples = openapi3.Examples{}
+		}
+		c.Examples[name] = example
+	}
+	for _, s := range srv.namedSchemas {
+		if err := st.schemas.register(s.t, s.name); err != nil {
+			return err
+		}
+	}
+	for _, w := range srv.webhooks {
+		if err := st.addWebhook(w.name, &w.route); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parameterRefs replaces parameters matching a parameter component with $ref.
+func (st *routerState) parameterRefs(params openapi3.Parameters) openapi3.Parameters {
+	refs := make(openapi3.Parameters, 0, len(params))
+	for _, param := range params {
+		refs = append(refs, param)
+		for name, component := range st.docRoot.Components.Parameters {
+			if component.Value.In == param.Value.In && component.Value.Name == param.Value.Name {
+				refs[len(refs)-1] = &openapi3.ParameterRef{
+					Ref:   "#/components/parameters/" + name,
+					Value: component.Value,
+				}
+			}
+		}
+	}
+	return refs
+}
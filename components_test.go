@@ -0,0 +1,90 @@
+package docrouter
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponents(t *testing.T) {
+	type Star struct {
+		Name string `json:"name"`
+	}
+	type Error struct {
+		Message string `json:"message"`
+	}
+	type Parameters struct {
+		TraceID string `docrouter:"name:X-Trace-Id; kind:header"`
+	}
+
+	router := New(DefaultOptions)
+	require.NoError(t, router.AddSchema("Star", Star{}))
+	require.NoError(t, router.AddParameter("TraceID", &openapi3.Parameter{
+		Name:        "X-Trace-Id",
+		In:          openapi3.ParameterInHeader,
+		Description: "Request trace identifier",
+		Schema:      openapi3.NewStringSchema().NewRef(),
+	}))
+	require.NoError(t, router.AddResponse("NotFound", openapi3.NewResponse().
+		WithDescription("Not found").
+		WithJSONSchema(openapi3.NewObjectSchema().WithProperty("message", openapi3.NewStringSchema()))))
+	require.NoError(t, router.AddHeader("RequestID", &openapi3.Header{
+		Parameter: openapi3.Parameter{Schema: openapi3.NewStringSchema().NewRef()},
+	}))
+	require.NoError(t, router.AddExample("Sun", openapi3.NewExample(Star{Name: "Sun"})))
+
+	assert.Error(t, router.AddSchema("Star", Error{}), "duplicate name")
+	assert.Error(t, router.AddResponse("NotFound", openapi3.NewResponse()), "duplicate name")
+	assert.Error(t, router.AddExample("invalid name!", openapi3.NewExample(nil)), "invalid name")
+
+	noop := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	require.NoError(t, router.AddRoute(Route{
+		Path:         "/stars",
+		Methods:      []string{http.MethodPost},
+		Parameters:   &Parameters{},
+		RequestBody:  &Star{},
+		ResponseBody: &Star{},
+		Summary:      "Create star",
+		Responses:    map[string]string{"404": "NotFound"},
+		Handler:      noop,
+	}))
+	require.Error(t, router.AddRoute(Route{
+		Path:      "/planets",
+		Methods:   []string{http.MethodGet},
+		Summary:   "List planets",
+		Responses: map[string]string{"500": "Unknown"},
+		Handler:   noop,
+	}), "unknown response")
+
+	assertDoc := func(t *testing.T, router *Router) {
+		doc := router.OpenAPI()
+		require.NoError(t, doc.Validate(context.Background()))
+		op := doc.Paths["/stars"].Post
+		require.NotNil(t, op)
+		assert.Equal(t, "#/components/schemas/Star", op.RequestBody.Value.Content.Get("application/json").Schema.Ref)
+		assert.Equal(t, "#/components/schemas/Star", op.Responses["200"].Value.Content.Get("application/json").Schema.Ref)
+		assert.Equal(t, "#/components/responses/NotFound", op.Responses["404"].Ref)
+		assert.Equal(t, "#/components/parameters/TraceID", op.Parameters[0].Ref)
+		assert.Contains(t, doc.Components.Headers, "RequestID")
+		assert.Contains(t, doc.Components.Examples, "Sun")
+	}
+	assertDoc(t, router)
+
+	t.Run("components survive replace", func(t *testing.T) {
+		require.NoError(t, router.Replace([]Route{{
+			Path:         "/stars",
+			Methods:      []string{http.MethodPost},
+			Parameters:   &Parameters{},
+			RequestBody:  &Star{},
+			ResponseBody: &Star{},
+			Summary:      "Create star",
+			Responses:    map[string]string{"404": "NotFound"},
+			Handler:      noop,
+		}}))
+		assertDoc(t, router)
+	})
+}
@@ -0,0 +1,229 @@
+package docrouter
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CompressionOptions configures compression of responses negotiated
+// with Accept-Encoding request header. Routes opt out with DisableCompression.
+type CompressionOptions struct {
+	// MinSize is size of the smallest compressed response in bytes,
+	// smaller responses are sent as they are. Flushed responses are compressed
+	// regardless of their size.
+	MinSize int
+	// Encoders add content codings, e.g. "br", or replace gzip and deflate.
+	// They are preferred over gzip and deflate when the client accepts them equally.
+	Encoders map[string]Encoder
+}
+
+// Encoder creates writer compressing data written to w.
+// Encoders implementing Flush() error support streaming responses.
+type Encoder func(w io.Writer) io.WriteCloser
+
+func (o *CompressionOptions) encoder(coding string) Encoder {
+	if e, found := o.Encoders[coding]; found {
+		return e
+	}
+	switch coding {
+	case "gzip":
+		return func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }
+	case "deflate":
+		// HTTP deflate coding is zlib format, not raw DEFLATE
+		return func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }
+	}
+	return nil
+}
+
+// codings lists supported content codings by preference.
+func (o *CompressionOptions) codings() []string {
+	codings := []string{}
+	for coding := range o.Encoders {
+		if coding != "gzip" && coding != "deflate" {
+			codings = append(codings, coding)
+		}
+	}
+	sort.Strings(codings)
+	return append(codings, "gzip", "deflate")
+}
+
+// negotiate returns the preferred coding accepted by the client, empty when there's none.
+func (o *CompressionOptions) negotiate(acceptEncoding string) string {
+	accepted := map[string]float64{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		fields := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(fields[0]))
+		if coding == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		accepted[coding] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, coding := range o.codings() {
+		q, found := accepted[coding]
+		if !found {
+			q, found = accepted["*"]
+		}
+		if found && q > bestQ {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
+
+func (o *CompressionOptions) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		coding := o.negotiate(r.Header.Get("Accept-Encoding"))
+		if coding == "" || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		cw := &compressWriter{ResponseWriter: w, opts: o, coding: coding}
+		defer cw.close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// compressWriter buffers the response until it's known whether to compress it.
+type compressWriter struct {
+	http.ResponseWriter
+	opts   *CompressionOptions
+	coding string
+
+	status  int
+	buf     bytes.Buffer
+	decided bool
+	encoder io.WriteCloser
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if status < http.StatusOK && !cw.decided {
+		// informational responses precede the final one
+		cw.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if cw.status != 0 {
+		return
+	}
+	cw.status = status
+	if !bodyAllowed(status) {
+		cw.start(false)
+	}
+}
+
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if cw.status == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.decided {
+		return cw.write(b)
+	}
+	n, _ := cw.buf.Write(b)
+	if cw.buf.Len() >= cw.opts.MinSize {
+		if err := cw.start(true); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+func (cw *compressWriter) write(b []byte) (int, error) {
+	if cw.encoder != nil {
+		return cw.encoder.Write(b)
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+// start sends headers and the buffered data, compressed when asked for
+// and the handler didn't encode the response itself. Byte ranges
+// aren't compressed, as their offsets refer to the uncompressed content.
+func (cw *compressWriter) start(compress bool) error {
+	cw.decided = true
+	h := cw.Header()
+	partial := cw.status == http.StatusPartialContent || h.Get("Content-Range") != ""
+	if compress && !partial && h.Get("Content-Encoding") == "" {
+		if e := cw.opts.encoder(cw.coding); e != nil {
+			h.Set("Content-Encoding", cw.coding)
+			h.Del("Content-Length")
+			if etag := h.Get("ETag"); strings.HasPrefix(etag, `"`) {
+				// encoded representation isn't byte for byte the same
+				h.Set("ETag", "W/"+etag)
+			}
+			cw.encoder = e(cw.ResponseWriter)
+		}
+	}
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+	if cw.buf.Len() == 0 {
+		return nil
+	}
+	_, err := cw.write(cw.buf.Bytes())
+	cw.buf.Reset()
+	return err
+}
+
+// Flush starts compressing the response, as its final size is unknown.
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		if err := cw.start(true); err != nil {
+			return
+		}
+	}
+	if f, ok := cw.encoder.(interface{ Flush() error }); ok {
+		if err := f.Flush(); err != nil {
+			return
+		}
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (cw *compressWriter) close() {
+	if !cw.decided {
+		// smaller than MinSize
+		_ = cw.start(false)
+	}
+	if cw.encoder != nil {
+		_ = cw.encoder.Close()
+	}
+}
+
+// Hijack is used e.g. by websocket upgrades, the connection isn't compressed then.
+func (cw *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := hijack(cw.ResponseWriter)
+	if err == nil && !cw.decided {
+		cw.decided = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap is used by http.ResponseController.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+func bodyAllowed(status int) bool {
+	return status >= http.StatusOK && status != http.StatusNoContent && status != http.StatusNotModified
+}
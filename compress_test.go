@@ -0,0 +1,179 @@
+package docrouter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type upperEncoder struct{ w io.Writer }
+
+func (e upperEncoder) Write(b []byte) (int, error) { return e.w.Write(bytes.ToUpper(b)) }
+func (e upperEncoder) Close() error                { return nil }
+
+func TestCompression(t *testing.T) {
+	var observedSize float64
+	opts := DefaultOptions
+	opts.Compression = &CompressionOptions{MinSize: 10}
+	opts.Observer = &MetricsObserver{ObserveResponseSize: func(labels map[string]string, bytes float64) {
+		observedSize = bytes
+	}}
+	router := New(opts)
+	body := strings.Repeat("star ", 100)
+	require.NoError(t, router.AddRoute(Route{
+		Path:    "/stars",
+		Methods: []string{http.MethodGet},
+		Summary: "List stars",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("ETag", `"stars"`)
+			w.Header().Set("Content-Length", "500")
+			_, _ = w.Write([]byte(body))
+		}),
+	}))
+	require.NoError(t, router.AddRoute(Route{
+		Path:    "/sun",
+		Methods: []string{http.MethodGet},
+		Summary: "Get sun",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("sun"))
+		}),
+	}))
+	require.NoError(t, router.AddRoute(Route{
+		Path:    "/range",
+		Methods: []string{http.MethodGet},
+		Summary: "Get range",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Range", "bytes 0-99/500")
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write([]byte(body[:100]))
+		}),
+	}))
+	require.NoError(t, router.AddRoute(Route{
+		Path:               "/archive",
+		Methods:            []string{http.MethodGet},
+		Summary:            "Get archive",
+		DisableCompression: true,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(body))
+		}),
+	}))
+
+	get := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/stars", "deflate;q=0.5, gzip")
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+	assert.Empty(t, w.Header().Get("Content-Length"))
+	assert.Equal(t, `W/"stars"`, w.Header().Get("ETag"))
+	zr, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	decoded, err := ioutil.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(decoded))
+	assert.Equal(t, float64(len(body)), observedSize, "observer sees uncompressed size")
+
+	w = get("/stars", "deflate")
+	assert.Equal(t, "deflate", w.Header().Get("Content-Encoding"))
+	zlr, err := zlib.NewReader(w.Body)
+	require.NoError(t, err, "deflate coding is zlib format")
+	decoded, err = ioutil.ReadAll(zlr)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(decoded))
+
+	w = get("/range", "gzip")
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Encoding"), "byte ranges aren't compressed")
+	assert.Equal(t, body[:100], w.Body.String())
+
+	w = get("/stars", "gzip;q=0, identity")
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, body, w.Body.String())
+
+	w = get("/sun", "gzip")
+	assert.Empty(t, w.Header().Get("Content-Encoding"), "responses smaller than MinSize aren't compressed")
+	assert.Equal(t, "sun", w.Body.String())
+	assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+
+	w = get("/archive", "gzip")
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Empty(t, w.Header().Get("Vary"))
+	assert.Equal(t, body, w.Body.String())
+}
+
+func TestCompressionStreaming(t *testing.T) {
+	opts := DefaultOptions
+	opts.Compression = &CompressionOptions{MinSize: 1024}
+	router := New(opts)
+	require.NoError(t, router.AddRoute(Route{
+		Path:    "/events",
+		Methods: []string{http.MethodGet},
+		Summary: "Stream events",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("data: sun\n\n"))
+			w.(http.Flusher).Flush()
+			_, _ = w.Write([]byte("data: moon\n\n"))
+		}),
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.True(t, w.Flushed)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"), "flushed responses are compressed")
+	zr, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	decoded, err := ioutil.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, "data: sun\n\ndata: moon\n\n", string(decoded))
+}
+
+func TestCompressionNegotiate(t *testing.T) {
+	opts := &CompressionOptions{Encoders: map[string]Encoder{
+		"br": func(w io.Writer) io.WriteCloser { return upperEncoder{w} },
+	}}
+	assert.Equal(t, "br", opts.negotiate("gzip, deflate, br"))
+	assert.Equal(t, "gzip", opts.negotiate("gzip, br;q=0.5"))
+	assert.Equal(t, "deflate", opts.negotiate("DEFLATE"))
+	assert.Equal(t, "br", opts.negotiate("*"))
+	assert.Equal(t, "", opts.negotiate("identity"))
+	assert.Equal(t, "", opts.negotiate("*;q=0"))
+	assert.Equal(t, "", opts.negotiate(""))
+}
+
+func TestCompressionHijack(t *testing.T) {
+	opts := DefaultOptions
+	opts.Compression = &CompressionOptions{}
+	router := New(opts)
+	require.NoError(t, router.AddRoute(Route{
+		Path:    "/socket",
+		Methods: []string{http.MethodGet},
+		Summary: "Socket",
+		Handler: switchingProtocolsHandler,
+	}))
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/socket", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+}
@@ -0,0 +1,141 @@
+package docrouter
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSOptions configures Cross-Origin Resource Sharing for all routes.
+//
+// Allowed methods are not configurable, they are taken from the routes
+// registered on the requested path.
+type CORSOptions struct {
+	// AllowedOrigins lists origins allowed to make cross-origin requests.
+	// "*" allows any origin.
+	AllowedOrigins []string
+	// AllowedHeaders lists request headers allowed in cross-origin requests.
+	// "*" allows any header requested by the client.
+	AllowedHeaders []string
+	// ExposedHeaders lists response headers readable by the client.
+	ExposedHeaders []string
+	// AllowCredentials allows cookies and HTTP authentication.
+	// It can't be combined with the "*" origin, Router.Validate, Freeze
+	// and NewFromSpec fail otherwise.
+	AllowCredentials bool
+	// MaxAge says how long the result of a preflight request can be cached.
+	// Zero omits the Access-Control-Max-Age header.
+	MaxAge time.Duration
+}
+
+func (c *CORSOptions) validate() error {
+	if c.AllowCredentials && containsString(c.AllowedOrigins, "*") {
+		return fmt.Errorf("cors credentials can't be allowed for any origin")
+	}
+	return nil
+}
+
+func (c *CORSOptions) originAllowed(origin string) bool {
+	for _, o := range c.AllowedOrigins {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CORSOptions) headerAllowed(header string) bool {
+	for _, h := range c.AllowedHeaders {
+		if h == "*" || strings.EqualFold(h, header) {
+			return true
+		}
+	}
+	return false
+}
+
+// setOriginHeaders sets headers shared by preflight and actual responses.
+func (c *CORSOptions) setOriginHeaders(w http.ResponseWriter, origin string) {
+	allowOrigin := origin
+	if containsString(c.AllowedOrigins, "*") {
+		allowOrigin = "*"
+	}
+	w.Header().Set("Access-Control-Allow-Origin", allowOrigin)
+	if c.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func (c *CORSOptions) handlePreflight(w http.ResponseWriter, r *http.Request, methods []string) {
+	w.Header().Add("Vary", "Origin")
+	w.Header().Add("Vary", "Access-Control-Request-Method")
+	w.Header().Add("Vary", "Access-Control-Request-Headers")
+
+	origin := r.Header.Get("Origin")
+	if !c.originAllowed(origin) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	if !containsString(methods, r.Header.Get("Access-Control-Request-Method")) {
+		w.Header().Set("Allow", strings.Join(methods, ", "))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	requestedHeaders := splitHeaderList(r.Header.Get("Access-Control-Request-Headers"))
+	for _, h := range requestedHeaders {
+		if !c.headerAllowed(h) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+	}
+
+	c.setOriginHeaders(w, origin)
+	w.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+	if len(requestedHeaders) > 0 {
+		w.Header().Set("Access-Control-Allow-Headers", strings.Join(requestedHeaders, ", "))
+	}
+	if c.MaxAge > 0 {
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(c.MaxAge/time.Second)))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// middleware sets CORS headers on responses to actual (non-preflight) requests.
+func (c *CORSOptions) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Origin")
+		if origin := r.Header.Get("Origin"); origin != "" && c.originAllowed(origin) {
+			c.setOriginHeaders(w, origin)
+			if len(c.ExposedHeaders) > 0 {
+				w.Header().Set("Access-Control-Expose-Headers", strings.Join(c.ExposedHeaders, ", "))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func isPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions &&
+		r.Header.Get("Origin") != "" &&
+		r.Header.Get("Access-Control-Request-Method") != ""
+}
+
+func splitHeaderList(v string) []string {
+	headers := []string{}
+	for _, h := range strings.Split(v, ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			headers = append(headers, h)
+		}
+	}
+	return headers
+}
+
+func containsString(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
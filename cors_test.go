@@ -0,0 +1,169 @@
+package docrouter
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoOptionsAndHead(t *testing.T) {
+	opts := DefaultOptions
+	opts.AutoOptions = true
+	opts.AutoHead = true
+	router := New(opts)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "stars")
+	})
+	require.NoError(t, router.AddRoute(Route{
+		Path:    "/stars",
+		Methods: []string{http.MethodGet},
+		Summary: "Get stars",
+		Handler: handler,
+	}))
+	require.NoError(t, router.AddRoute(Route{
+		Path:    "/stars",
+		Methods: []string{http.MethodPost},
+		Summary: "Create star",
+		Handler: handler,
+	}))
+
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	t.Run("options", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodOptions, ts.URL+"/stars", nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		assert.Equal(t, "GET, HEAD, POST, OPTIONS", resp.Header.Get("Allow"))
+	})
+
+	t.Run("head", func(t *testing.T) {
+		resp, err := http.Head(ts.URL + "/stars")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		assert.Empty(t, respBytes)
+	})
+}
+
+func TestCORS(t *testing.T) {
+	opts := DefaultOptions
+	opts.CORS = &CORSOptions{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowedHeaders:   []string{"Content-Type"},
+		ExposedHeaders:   []string{"X-Request-Id"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+	router := New(opts)
+
+	type StarParameters struct {
+		StarID int `docrouter:"name:starId; kind:path"`
+	}
+	require.NoError(t, router.AddRoute(Route{
+		Path:       "/stars/{starId}",
+		Methods:    []string{http.MethodGet, http.MethodPut},
+		Parameters: &StarParameters{},
+		Summary:    "Star",
+		Handler:    http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	}))
+
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	preflight := func(t *testing.T, origin, method, headers string) *http.Response {
+		req, err := http.NewRequest(http.MethodOptions, ts.URL+"/stars/5", nil)
+		require.NoError(t, err)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", method)
+		if headers != "" {
+			req.Header.Set("Access-Control-Request-Headers", headers)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	t.Run("preflight", func(t *testing.T) {
+		resp := preflight(t, "https://app.example.com", http.MethodPut, "content-type")
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		assert.Equal(t, "https://app.example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "GET, PUT, OPTIONS", resp.Header.Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "content-type", resp.Header.Get("Access-Control-Allow-Headers"))
+		assert.Equal(t, "true", resp.Header.Get("Access-Control-Allow-Credentials"))
+		assert.Equal(t, "600", resp.Header.Get("Access-Control-Max-Age"))
+	})
+
+	t.Run("preflight unknown origin", func(t *testing.T) {
+		resp := preflight(t, "https://evil.example.com", http.MethodPut, "")
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("preflight method not registered", func(t *testing.T) {
+		resp := preflight(t, "https://app.example.com", http.MethodDelete, "")
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+
+	t.Run("preflight header not allowed", func(t *testing.T) {
+		resp := preflight(t, "https://app.example.com", http.MethodPut, "X-Secret")
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("actual request", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+"/stars/5", nil)
+		require.NoError(t, err)
+		req.Header.Set("Origin", "https://app.example.com")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "https://app.example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "X-Request-Id", resp.Header.Get("Access-Control-Expose-Headers"))
+	})
+
+	t.Run("options without preflight", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodOptions, ts.URL+"/stars/5", nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+}
+
+func TestCORSCredentialsAnyOrigin(t *testing.T) {
+	opts := DefaultOptions
+	opts.CORS = &CORSOptions{
+		AllowedOrigins:   []string{"*"},
+		AllowCredentials: true,
+	}
+	router := New(opts)
+	require.NoError(t, router.AddRoute(Route{
+		Path:    "/stars",
+		Methods: []string{http.MethodGet},
+		Summary: "Get stars",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	}))
+	assert.Error(t, router.Validate(context.Background()))
+	assert.Error(t, router.Freeze())
+
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(starsSpec))
+	require.NoError(t, err)
+	_, err = NewFromSpec(doc, opts)
+	assert.Error(t, err)
+}
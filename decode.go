@@ -1,7 +1,9 @@
 package docrouter
 
 import (
+	"encoding"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"reflect"
 	"strconv"
@@ -11,6 +13,14 @@ import (
 )
 
 func DecodeParams(structPtr interface{}, req *http.Request) error {
+	err := decodeParams(structPtr, req)
+	if err != nil {
+		reportDecodeError(req.Context(), err)
+	}
+	return err
+}
+
+func decodeParams(structPtr interface{}, req *http.Request) error {
 	if req.URL == nil {
 		return fmt.Errorf("invalid request - req.URL is nil")
 	}
@@ -74,6 +84,16 @@ func convertAndSetStructField(sVal *reflect.Value, fieldName, valueStr string) e
 		return fmt.Errorf("can't set field %q", fieldName)
 	}
 
+	if structField.Addr().Type().Implements(textUnmarshalerType) {
+		if valueStr == "" {
+			return nil
+		}
+		if err := structField.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(valueStr)); err != nil {
+			return fmt.Errorf("unmarshal %q: %v", valueStr, err)
+		}
+		return nil
+	}
+
 	switch structField.Kind() {
 	case reflect.Int:
 		intVal, err := strconv.Atoi(valueStr)
@@ -94,3 +114,33 @@ func convertAndSetStructField(sVal *reflect.Value, fieldName, valueStr string) e
 	}
 	return nil
 }
+
+// DecodeBody decodes JSON request body into the value pointed to by v.
+//
+// Interfaces registered with RegisterOneOf are decoded into the concrete type
+// selected by the discriminator property.
+func DecodeBody(req *http.Request, v interface{}) error {
+	err := decodeBody(req, v)
+	if err != nil {
+		reportDecodeError(req.Context(), err)
+	}
+	return err
+}
+
+func decodeBody(req *http.Request, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("expected non-nil pointer, got %T", v)
+	}
+	if req.Body == nil {
+		return fmt.Errorf("invalid request - req.Body is nil")
+	}
+	data, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return fmt.Errorf("read body: %v", err)
+	}
+	if err := unmarshalJSON(data, rv.Elem()); err != nil {
+		return fmt.Errorf("decode body: %w", err)
+	}
+	return nil
+}
@@ -52,7 +52,7 @@ func TestDecodeParams(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	ts := httptest.NewServer(server.muxRouter)
+	ts := httptest.NewServer(server)
 	defer ts.Close()
 
 	u := ts.URL + fmt.Sprintf("/example-param/%s?starid=%d&potato=%t", expectedFishName, expectedStarID, expectedPotato)
@@ -0,0 +1,100 @@
+package docrouter
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Deprecation describes deprecated route in response headers.
+type Deprecation struct {
+	// Since is time since the route is deprecated, sent in Deprecation header (RFC 9745)
+	Since time.Time
+	// Sunset is time when the route stops being served, sent in Sunset header (RFC 8594)
+	Sunset time.Time
+	// Link to migration guide, sent in Link header with deprecation relation type
+	Link string
+}
+
+// DeprecatedUse is reported by Options.OnDeprecatedUse.
+type DeprecatedUse struct {
+	OperationID string
+	// Version is set when the API version is deprecated
+	Version string
+	// Parameter and In are set when a deprecated parameter is sent,
+	// both are empty when the operation is deprecated.
+	Parameter string
+	In        string
+}
+
+func (d *Deprecation) setHeaders(h http.Header) {
+	setDeprecationHeaders(h, d.Since, d.Sunset)
+	if d.Link != "" {
+		h.Add("Link", "<"+d.Link+`>; rel="deprecation"`)
+	}
+}
+
+func setDeprecationHeaders(h http.Header, since, sunset time.Time) {
+	if !since.IsZero() {
+		h.Set("Deprecation", "@"+strconv.FormatInt(since.Unix(), 10))
+	}
+	if !sunset.IsZero() {
+		h.Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+	}
+}
+
+// deprecationMiddleware sets headers of deprecated route and reports use of
+// the route and its deprecated parameters. It returns nil when there's nothing to do.
+func (srv *Router) deprecationMiddleware(route *Route, params openapi3.Parameters) func(http.Handler) http.Handler {
+	deprecatedParams := []*openapi3.Parameter{}
+	for _, param := range params {
+		if param.Value != nil && param.Value.Deprecated {
+			deprecatedParams = append(deprecatedParams, param.Value)
+		}
+	}
+	report := srv.opts.OnDeprecatedUse
+	if !route.Deprecated && (report == nil || len(deprecatedParams) == 0) {
+		return nil
+	}
+
+	operationID := uniqueOperationID(route)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if route.Deprecated {
+				if route.Deprecation != nil {
+					route.Deprecation.setHeaders(w.Header())
+				}
+				if report != nil {
+					report(r, DeprecatedUse{OperationID: operationID})
+				}
+			}
+			if report != nil {
+				for _, param := range deprecatedParams {
+					if paramSent(r, param) {
+						report(r, DeprecatedUse{OperationID: operationID, Parameter: param.Name, In: param.In})
+					}
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// paramSent reports whether the request contains the parameter.
+func paramSent(r *http.Request, param *openapi3.Parameter) bool {
+	switch param.In {
+	case openapi3.ParameterInQuery:
+		_, found := r.URL.Query()[param.Name]
+		return found
+	case openapi3.ParameterInHeader:
+		return r.Header.Get(param.Name) != ""
+	case openapi3.ParameterInCookie:
+		_, err := r.Cookie(param.Name)
+		return err == nil
+	default:
+		// path parameters are always sent
+		return true
+	}
+}
@@ -0,0 +1,121 @@
+package docrouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeprecation(t *testing.T) {
+	var mu sync.Mutex
+	uses := []DeprecatedUse{}
+	opts := DefaultOptions
+	opts.OnDeprecatedUse = func(r *http.Request, use DeprecatedUse) {
+		mu.Lock()
+		defer mu.Unlock()
+		uses = append(uses, use)
+	}
+	router := New(opts)
+
+	type params struct {
+		Limit  int    `docrouter:"name: limit; kind: query"`
+		Filter string `docrouter:"name: filter; kind: query; deprecated: true"`
+		Token  string `docrouter:"name: X-Token; kind: header; deprecated: true"`
+	}
+	require.NoError(t, router.AddRoute(Route{
+		Path:        "/stars",
+		Methods:     []string{http.MethodGet},
+		Parameters:  &params{},
+		Summary:     "List stars",
+		OperationID: "listStars",
+		Handler:     http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	}))
+	require.NoError(t, router.AddRoute(Route{
+		Path:        "/stars/search",
+		Methods:     []string{http.MethodGet},
+		Summary:     "Search stars",
+		OperationID: "searchStars",
+		Deprecated:  true,
+		Deprecation: &Deprecation{
+			Since:  time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			Sunset: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+			Link:   "https://example.com/migrate",
+		},
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	}))
+
+	doc := router.OpenAPI()
+	assert.True(t, doc.Paths["/stars/search"].Get.Deprecated)
+	list := doc.Paths["/stars"].Get
+	assert.False(t, list.Deprecated)
+	assert.True(t, list.Parameters.GetByInAndName("query", "filter").Deprecated)
+	assert.False(t, list.Parameters.GetByInAndName("query", "limit").Deprecated)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stars/search", nil))
+	assert.Equal(t, "@1767225600", rec.Header().Get("Deprecation"))
+	assert.Equal(t, "Fri, 01 Jan 2027 00:00:00 GMT", rec.Header().Get("Sunset"))
+	assert.Equal(t, `<https://example.com/migrate>; rel="deprecation"`, rec.Header().Get("Link"))
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stars?limit=5", nil))
+	assert.Empty(t, rec.Header().Get("Deprecation"))
+
+	req := httptest.NewRequest(http.MethodGet, "/stars?filter=red", nil)
+	req.Header.Set("X-Token", "secret")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, []DeprecatedUse{
+		{OperationID: "searchStars"},
+		{OperationID: "listStars", Parameter: "filter", In: "query"},
+		{OperationID: "listStars", Parameter: "X-Token", In: "header"},
+	}, uses)
+
+	t.Run("invalid tag", func(t *testing.T) {
+		type params struct {
+			Filter string `docrouter:"name: filter; kind: query; deprecated: soon"`
+		}
+		err := router.AddRoute(Route{
+			Path:       "/planets",
+			Methods:    []string{http.MethodGet},
+			Parameters: &params{},
+			Summary:    "List planets",
+			Handler:    http.NotFoundHandler(),
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("deprecated version", func(t *testing.T) {
+		var used []DeprecatedUse
+		opts := DefaultOptions
+		opts.Versioning = &Versioning{Versions: []APIVersion{{Name: "v1", Deprecation: time.Now()}, {Name: "v2"}}}
+		opts.OnDeprecatedUse = func(r *http.Request, use DeprecatedUse) { used = append(used, use) }
+		router := New(opts)
+		require.NoError(t, router.AddRoute(Route{
+			Path:        "/stars",
+			Methods:     []string{http.MethodGet},
+			Summary:     "List stars",
+			OperationID: "listStars",
+			Handler:     http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		}))
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v2/stars", nil))
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/stars", nil))
+		assert.Equal(t, []DeprecatedUse{{OperationID: "listStars", Version: "v1"}}, used)
+	})
+}
+
+func TestDeprecationWithoutDeprecated(t *testing.T) {
+	err := New(DefaultOptions).AddRoute(Route{
+		Path:        "/stars",
+		Methods:     []string{http.MethodGet},
+		Summary:     "List stars",
+		Deprecation: &Deprecation{Link: "https://example.com/migration"},
+		Handler:     http.NotFoundHandler(),
+	})
+	assert.Error(t, err)
+}
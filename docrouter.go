@@ -1,9 +1,13 @@
 package docrouter
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	validation "github.com/go-ozzo/ozzo-validation"
@@ -12,87 +16,562 @@ import (
 )
 
 type Router struct {
-	opts      Options
+	opts Options
+
+	// state holds *routerState, it's swapped by Replace and RemoveRoute
+	state atomic.Value
+
+	// sharedComponents, namedSchemas and webhooks are added with AddParameter,
+	// AddSchema, AddWebhook etc. and applied to every state
+	sharedComponents openapi3.Components
+	namedSchemas     []namedSchema
+	webhooks         []namedWebhook
+
+	// specBindings are set for routers created with NewFromSpec
+	specBindings map[string]*specBinding
+	bindErrs     []error
+	// bindingCheck is result of checking bindings on serve, replaced by Bind
+	bindingCheck *serveCheck
+
+	// rateLimits keeps buckets of rate limits without their own store
+	rateLimits *MemoryRateLimitStore
+
+	// writeMu serializes modifications of the router
+	writeMu sync.Mutex
+	// mu guards in place modifications of the state and bindings until the router is frozen
+	mu     sync.RWMutex
+	frozen int32
+}
+
+// routerState is a set of routes with their document and handlers.
+type routerState struct {
 	docRoot   *openapi3.T
 	muxRouter *mux.Router
+	// handlerRouters are muxRouter or its subrouters for servers, see Options.ServerMatching
+	handlerRouters []*mux.Router
+
+	routes []RouteInfo
+	// added keeps routes as passed to AddRoute, so the state can be rebuilt
+	added []Route
+
+	schemas *schemaGenerator
+
+	// pathMethods keeps methods registered for each path template,
+	// used for answering OPTIONS and CORS preflight requests
+	pathMethods map[string][]string
+
+	// specJSON is serialized docRoot, set for frozen routers only
+	specJSON []byte
+	// versionJSON are serialized documents of API versions, set for frozen routers only
+	versionJSON map[string][]byte
+
+	// validation is result of Options.ValidateOnServe, replaced when the state changes
+	validation *serveCheck
+	// published is set when docRoot is returned by OpenAPI,
+	// the state isn't modified in place then
+	published int32
 }
 
 func New(opts Options) *Router {
+	srv := &Router{opts: opts}
+	srv.state.Store(newRouterState(srv.newDoc()))
+	return srv
+}
+
+func (srv *Router) newDoc() *openapi3.T {
 	docRoot := openapi3.T{
-		OpenAPI: "3.0.0",
+		OpenAPI: OpenAPI30,
 		Info: &openapi3.Info{
-			Title:   opts.Title,
-			Version: opts.Version,
+			Title:          srv.opts.Title,
+			Version:        srv.opts.Version,
+			Description:    srv.opts.Description,
+			TermsOfService: srv.opts.TermsOfService,
+		},
+		ExternalDocs: srv.opts.ExternalDocs.openAPI3(),
+		ExtensionProps: openapi3.ExtensionProps{
+			Extensions: copyExtensions(srv.opts.Extensions),
 		},
 	}
-	for _, server := range opts.Servers {
-		docRoot.AddServer(&openapi3.Server{
-			URL:         server.URL,
-			Description: server.Description,
+	if c := srv.opts.Contact; c != nil {
+		docRoot.Info.Contact = &openapi3.Contact{Name: c.Name, URL: c.URL, Email: c.Email}
+	}
+	if l := srv.opts.License; l != nil {
+		docRoot.Info.License = &openapi3.License{Name: l.Name, URL: l.URL}
+	}
+	for _, tag := range srv.opts.Tags {
+		docRoot.Tags = append(docRoot.Tags, &openapi3.Tag{
+			Name:         tag.Name,
+			Description:  tag.Description,
+			ExternalDocs: tag.ExternalDocs.openAPI3(),
 		})
 	}
-	return &Router{
-		opts:      opts,
-		docRoot:   &docRoot,
-		muxRouter: mux.NewRouter(),
+	for _, server := range srv.opts.Servers {
+		docRoot.AddServer(server.openAPI3())
+	}
+	return &docRoot
+}
+
+func newRouterState(docRoot *openapi3.T) *routerState {
+	muxRouter := mux.NewRouter()
+	muxRouter.Use(releaseReadLock)
+	return &routerState{
+		docRoot:     docRoot,
+		muxRouter:   muxRouter,
+		schemas:     newSchemaGenerator(&docRoot.Components),
+		pathMethods: map[string][]string{},
+		validation:  &serveCheck{},
+	}
+}
+
+// newState creates empty state with components registered on the router.
+func (srv *Router) newState() (*routerState, error) {
+	st := newRouterState(srv.newDoc())
+	if err := srv.applyComponents(st); err != nil {
+		return nil, fmt.Errorf("apply components: %w", err)
 	}
+	return st, nil
+}
+
+// current returns the active state.
+func (srv *Router) current() *routerState {
+	return srv.state.Load().(*routerState)
 }
 
 func (srv *Router) AddRoute(route Route) error {
+	srv.writeMu.Lock()
+	defer srv.writeMu.Unlock()
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.isFrozen() {
+		return ErrFrozen
+	}
+	if srv.specBindings != nil {
+		return fmt.Errorf("routes of a router created from spec are defined by the spec, use Bind")
+	}
+	st, err := srv.writableState()
+	if err != nil {
+		return err
+	}
+	if err := srv.addRoute(st, route); err != nil {
+		return err
+	}
+	srv.state.Store(st)
+	return nil
+}
+
+// writableState returns the current state for modification. When its document
+// was returned by OpenAPI, a copy of the state is returned, which is swapped
+// with the current one by the caller.
+func (srv *Router) writableState() (*routerState, error) {
+	st := srv.current()
+	if atomic.LoadInt32(&st.published) == 0 {
+		return st, nil
+	}
+	copied, err := srv.newState()
+	if err != nil {
+		return nil, err
+	}
+	for _, route := range st.added {
+		if err := srv.addRoute(copied, route); err != nil {
+			return nil, fmt.Errorf("copy route %q: %w", route.Path, err)
+		}
+	}
+	return copied, nil
+}
+
+func (srv *Router) addRoute(st *routerState, route Route) error {
 	if err := srv.validateRoute(&route); err != nil {
 		return fmt.Errorf("route validation: %v", err)
 	}
-	if err := srv.addRouteToDoc(&route); err != nil {
+	// parameters are reflected once and shared by the doc, handler and route info
+	params, err := route.openAPI3Params()
+	if err != nil {
+		return fmt.Errorf("route validation: create route params: %w", err)
+	}
+	if err := validateRouteParams(&route, params); err != nil {
+		return fmt.Errorf("route validation: %v", err)
+	}
+	if err := srv.addRouteToDoc(st, route, params); err != nil {
 		return fmt.Errorf("adding route do doc: %v", err)
 	}
-	if err := srv.registerHandler(&route); err != nil {
+	if err := srv.registerHandler(st, &route, params); err != nil {
 		return fmt.Errorf("register handler: %v", err)
 	}
+	var versions []string
+	servedPath := route.Path
+	if v := srv.opts.Versioning; v != nil {
+		versions = v.routeVersions(&route)
+		servedPath = v.versionedPath(route.Path, versions[len(versions)-1])
+	}
+	st.addRouteInfo(&route, params, versions, servedPath)
+	st.added = append(st.added, route)
+	st.validation = &serveCheck{}
 
 	return nil
 }
 
-func (srv *Router) addRouteToDoc(route *Route) error {
-	params, err := route.openAPI3Params()
+// addRouteToDoc documents the route, versioned routers document the newest version.
+func (srv *Router) addRouteToDoc(st *routerState, route Route, params openapi3.Parameters) error {
+	v := srv.opts.Versioning
+	if v == nil {
+		if err := st.addRouteToDoc(&route, params); err != nil {
+			return err
+		}
+		return srv.addRecoverResponse(st, &route)
+	}
+	if !containsString(v.routeVersions(&route), v.newest()) {
+		return nil
+	}
+	return srv.addVersionedRouteToDoc(st, route, v.newest(), params)
+}
+
+// addRecoverResponse documents response of panicking handler of the documented route.
+func (srv *Router) addRecoverResponse(st *routerState, route *Route) error {
+	if srv.opts.Recover == nil {
+		return nil
+	}
+	docPath, err := openAPIPath(route.Path)
 	if err != nil {
-		return fmt.Errorf("create route params: %w", err)
+		return err
 	}
+	for _, method := range route.Methods {
+		operation := st.docRoot.Paths[docPath].GetOperation(method)
+		addProblemResponse(operation.Responses, "500", "Internal server error")
+	}
+	return nil
+}
+
+func (st *routerState) addRouteToDoc(route *Route, params openapi3.Parameters) error {
+	docPath, err := openAPIPath(route.Path)
+	if err != nil {
+		return fmt.Errorf("create doc path: %w", err)
+	}
+	params = st.parameterRefs(params)
+
+	st.schemas.forRoute(len(st.added))
+	requestBody := st.operationRequestBody(route)
+	responses, err := st.operationResponses(route)
+	if err != nil {
+		return err
+	}
+	extensions := copyExtensions(route.Extensions)
+	if route.Timeout > 0 {
+		if extensions == nil {
+			extensions = map[string]interface{}{}
+		}
+		extensions[timeoutExtension] = route.Timeout.Milliseconds()
+		addProblemResponse(responses, "503", "Request timed out")
+	}
+	if route.RateLimit != nil {
+		rateLimitResponse(responses)
+	}
+
 	for _, method := range route.Methods {
 		operation := openapi3.Operation{
 			Summary:     route.Summary,
 			Description: route.Description,
-			OperationID: uniqueOperationID(route),
+			OperationID: methodOperationID(route, method),
+			Tags:        route.Tags,
 			Parameters:  params,
-			Responses:   openapi3.NewResponses(),
+			RequestBody: requestBody,
+			Responses:   responses,
+			Deprecated:  route.Deprecated,
+			ExtensionProps: openapi3.ExtensionProps{
+				Extensions: copyExtensions(extensions),
+			},
+		}
+		if route.Cache != nil && cacheableMethod(method) {
+			route.Cache.documentCache(&operation)
 		}
-		srv.docRoot.AddOperation(route.Path, method, &operation)
+		st.docRoot.AddOperation(docPath, method, &operation)
 	}
 	return nil
 }
 
+func (st *routerState) operationRequestBody(route *Route) *openapi3.RequestBodyRef {
+	if route.RequestBody == nil {
+		return nil
+	}
+	return &openapi3.RequestBodyRef{
+		Value: openapi3.NewRequestBody().
+			WithRequired(true).
+			WithJSONSchemaRef(st.schemas.schemaRef(reflect.TypeOf(route.RequestBody))),
+	}
+}
+
+func (st *routerState) operationResponses(route *Route) (openapi3.Responses, error) {
+	responses := openapi3.NewResponses()
+	if route.ResponseBody != nil || len(route.Responses) > 0 {
+		// the default response is only a placeholder without description
+		responses = openapi3.Responses{}
+	}
+	if route.ResponseBody != nil {
+		schemaRef := st.schemas.schemaRef(reflect.TypeOf(route.ResponseBody))
+		responses["200"] = &openapi3.ResponseRef{
+			Value: openapi3.NewResponse().
+				WithDescription("Successful response").
+				WithJSONSchemaRef(schemaRef),
+		}
+	}
+	for status, name := range route.Responses {
+		component, found := st.docRoot.Components.Responses[name]
+		if !found {
+			return nil, fmt.Errorf("response %q of status %s is not registered", name, status)
+		}
+		responses[status] = &openapi3.ResponseRef{
+			Ref:   "#/components/responses/" + name,
+			Value: component.Value,
+		}
+	}
+	return responses, nil
+}
+
 func uniqueOperationID(route *Route) string {
+	if route.OperationID != "" {
+		return route.OperationID
+	}
 	// todo: ensure uniqueness
 	return strings.ToLower(strings.ReplaceAll(route.Summary, " ", "-"))
 }
 
-func (*Router) validateRoute(route *Route) error {
-	return validation.ValidateStruct(route,
+// methodOperationID is operation ID of the route method in the document,
+// operations of a route with several methods are suffixed with the method.
+// Explicit operation IDs are used as given, they are rejected on routes
+// with several methods.
+func methodOperationID(route *Route, method string) string {
+	if len(route.Methods) > 1 {
+		return uniqueOperationID(route) + "-" + strings.ToLower(method)
+	}
+	return uniqueOperationID(route)
+}
+
+// hasOperationID reports whether id identifies the route,
+// either by the route operation ID or by one of its documented operations.
+func hasOperationID(routeID string, methods []string, id string) bool {
+	if routeID == id {
+		return true
+	}
+	if len(methods) > 1 {
+		for _, method := range methods {
+			if routeID+"-"+strings.ToLower(method) == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (srv *Router) validateRoute(route *Route) error {
+	if err := validation.ValidateStruct(route,
 		validation.Field(&route.Handler, validation.NotNil),
 		validation.Field(&route.Path, validation.Required),
 		validation.Field(&route.Summary, validation.Required),
-	)
+	); err != nil {
+		return err
+	}
+	if err := validateExtensions(route.Extensions); err != nil {
+		return err
+	}
+	if route.OperationID != "" && len(route.Methods) > 1 {
+		return fmt.Errorf("operation ID %q can't be used for %d methods, register route for each method", route.OperationID, len(route.Methods))
+	}
+	if route.Deprecation != nil && !route.Deprecated {
+		return fmt.Errorf("deprecation is set on route which isn't deprecated")
+	}
+	if route.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+	if route.RateLimit != nil {
+		if err := route.RateLimit.validate(); err != nil {
+			return err
+		}
+	}
+	return srv.validateRouteVersions(route)
 }
 
-func (srv *Router) registerHandler(route *Route) error {
-	h := handlerWithMiddlewares(route.Handler, route.Middlewares)
-	srv.muxRouter.
-		Handle(route.Path, h).
-		Methods(route.Methods...)
+// validateRouteParams checks that path variables and parameters are consistent,
+// so DecodeParams doesn't silently read empty values and the doc is valid.
+func validateRouteParams(route *Route, params openapi3.Parameters) error {
+	pathVars, err := parsePathTemplate(route.Path)
+	if err != nil {
+		return err
+	}
+
+	pathVarNames := map[string]bool{}
+	for _, v := range pathVars {
+		if pathVarNames[v.name] {
+			return fmt.Errorf("path variable %q is used multiple times", v.name)
+		}
+		pathVarNames[v.name] = true
+		if params.GetByInAndName(openapi3.ParameterInPath, v.name) == nil {
+			return fmt.Errorf("path variable %q has no matching path parameter", v.name)
+		}
+	}
+
+	paramKinds := map[string]string{}
+	for _, paramRef := range params {
+		param := paramRef.Value
+		if param.Name == "" {
+			return fmt.Errorf("%s parameter has no name", param.In)
+		}
+		if param.In == "" {
+			return fmt.Errorf("parameter %q has no kind", param.Name)
+		}
+		name := param.Name
+		if param.In == openapi3.ParameterInHeader {
+			// header names are case insensitive
+			name = http.CanonicalHeaderKey(name)
+		}
+		if kind, found := paramKinds[name]; found {
+			if kind == param.In {
+				return fmt.Errorf("duplicate %s parameter %q", param.In, param.Name)
+			}
+			return fmt.Errorf("parameter %q is used in both %s and %s", param.Name, kind, param.In)
+		}
+		paramKinds[name] = param.In
+
+		if param.In == openapi3.ParameterInPath && !pathVarNames[param.Name] {
+			return fmt.Errorf("path parameter %q is missing in path %q", param.Name, route.Path)
+		}
+	}
 	return nil
 }
 
+func (srv *Router) registerHandler(st *routerState, route *Route, params openapi3.Parameters) error {
+	methods := append([]string{}, route.Methods...)
+	if srv.opts.AutoHead && containsString(methods, http.MethodGet) && !containsString(methods, http.MethodHead) {
+		methods = append(methods, http.MethodHead)
+	}
+
+	middlewares := route.Middlewares
+	if route.RateLimit != nil {
+		middlewares = append(append([]func(http.Handler) http.Handler{}, middlewares...), srv.rateLimitMiddleware(route))
+	}
+	if route.Cache != nil {
+		middlewares = append(append([]func(http.Handler) http.Handler{}, middlewares...), route.Cache.middleware)
+	}
+	if route.Timeout > 0 {
+		middlewares = append([]func(http.Handler) http.Handler{timeoutMiddleware(route.Timeout, srv.reportPanic)}, middlewares...)
+	}
+	if mw := srv.deprecationMiddleware(route, params); mw != nil {
+		middlewares = append([]func(http.Handler) http.Handler{mw}, middlewares...)
+	}
+	if srv.opts.CORS != nil {
+		middlewares = append([]func(http.Handler) http.Handler{srv.opts.CORS.middleware}, middlewares...)
+	}
+	routers, err := srv.handlerRouters(st)
+	if err != nil {
+		return err
+	}
+
+	// handler wraps route handler served on the path, router level middlewares
+	// come first, so the observer sees status of recovered panics and rejected
+	// versions, and uncompressed size of the response
+	handler := func(path string, versionMiddleware func(http.Handler) http.Handler) http.Handler {
+		outer := []func(http.Handler) http.Handler{}
+		if srv.opts.Compression != nil && !route.DisableCompression {
+			outer = append(outer, srv.opts.Compression.middleware)
+		}
+		if srv.opts.Observer != nil {
+			outer = append(outer, srv.observerMiddleware(route, path))
+		}
+		if srv.opts.AccessLog != nil {
+			outer = append(outer, srv.accessLogMiddleware(route, path, params))
+		}
+		if srv.opts.Recover != nil {
+			outer = append(outer, srv.opts.Recover.middleware)
+		}
+		if versionMiddleware != nil {
+			outer = append(outer, versionMiddleware)
+		}
+		return handlerWithMiddlewares(route.Handler, append(outer, middlewares...))
+	}
+
+	v := srv.opts.Versioning
+	if v == nil {
+		h := handler(route.Path, nil)
+		for _, r := range routers {
+			r.Handle(route.Path, h).Methods(methods...)
+		}
+		srv.registerOptionsHandler(st, routers, route.Path, methods)
+		return nil
+	}
+
+	versions := v.routeVersions(route)
+	if v.Scheme != VersionByPath {
+		h := handler(route.Path, v.middleware("", uniqueOperationID(route), srv.opts.OnDeprecatedUse))
+		for _, r := range routers {
+			r.Handle(route.Path, h).Methods(methods...).MatcherFunc(v.matcher(versions))
+		}
+		srv.registerOptionsHandler(st, routers, route.Path, methods)
+		return nil
+	}
+	for _, version := range versions {
+		path := v.versionedPath(route.Path, version)
+		h := handler(path, v.middleware(version, uniqueOperationID(route), srv.opts.OnDeprecatedUse))
+		for _, r := range routers {
+			r.Handle(path, h).Methods(methods...)
+		}
+		srv.registerOptionsHandler(st, routers, path, methods)
+	}
+	return nil
+}
+
+// registerOptionsHandler registers OPTIONS handler for the path when it's
+// seen for the first time. Methods of routes added later on the same path
+// are picked up by the handler at request time.
+func (srv *Router) registerOptionsHandler(st *routerState, routers []*mux.Router, path string, methods []string) {
+	_, seen := st.pathMethods[path]
+	st.pathMethods[path] = append(st.pathMethods[path], methods...)
+	if seen || containsString(methods, http.MethodOptions) {
+		return
+	}
+	if !srv.opts.AutoOptions && srv.opts.CORS == nil {
+		return
+	}
+	h := srv.optionsHandler(st, path)
+	for _, r := range routers {
+		r.Handle(path, h).Methods(http.MethodOptions)
+	}
+}
+
+func (srv *Router) optionsHandler(st *routerState, path string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		unlock := srv.readLock()
+		methods := append([]string{}, st.pathMethods[path]...)
+		unlock()
+		if !containsString(methods, http.MethodOptions) {
+			methods = append(methods, http.MethodOptions)
+		}
+		if srv.opts.CORS != nil && isPreflight(r) {
+			srv.opts.CORS.handlePreflight(w, r, methods)
+			return
+		}
+		w.Header().Set("Allow", strings.Join(methods, ", "))
+		if !srv.opts.AutoOptions {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
 func (srv *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	srv.muxRouter.ServeHTTP(w, r)
+	if !srv.isFrozen() {
+		// the read lock is released by releaseReadLock middleware as soon
+		// as the route is matched, so handlers can use the router
+		srv.mu.RLock()
+		release := &lockRelease{unlock: srv.mu.RUnlock}
+		defer release.release()
+		r = r.WithContext(context.WithValue(r.Context(), lockReleaseKey{}, release))
+	}
+	if srv.specBindings != nil && !srv.checkBindingsOnServe(w) {
+		return
+	}
+	if srv.opts.ValidateOnServe && !srv.validateOnServe(w) {
+		return
+	}
+	srv.current().muxRouter.ServeHTTP(w, r)
 }
 
 func handlerWithMiddlewares(handler http.Handler, middlewares []func(http.Handler) http.Handler) http.Handler {
@@ -8,7 +8,6 @@ import (
 	"net/http/httptest"
 	"testing"
 
-	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -17,8 +16,8 @@ func TestDocServer(t *testing.T) {
 	router := New(DefaultOptions)
 
 	type MyParameters struct {
-		StarID int  `docrouter:"name:starId;desc:Star identifier in CommonMark syntax. This can be potentially issue for longer descriptions.; example: 5; required: false; schemaMin: 3"`
-		Potato bool `docrouter:"name:potato;desc: This is bool!; example: true; required: true"`
+		StarID int  `docrouter:"name:starId;kind:query;desc:Star identifier in CommonMark syntax. This can be potentially issue for longer descriptions.; example: 5; required: false; schemaMin: 3"`
+		Potato bool `docrouter:"name:potato;kind:query;desc: This is bool!; example: true; required: true"`
 	}
 
 	const expectedHandlerOutput = "Hello star!"
@@ -34,14 +33,12 @@ func TestDocServer(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	router.muxRouter.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
-		methods, _ := route.GetMethods()
-		path, _ := route.GetPathTemplate()
-		fmt.Println("registered route", methods, path)
+	router.Walk(func(route RouteInfo) error {
+		fmt.Println("registered route", route.Methods, route.Path)
 		return nil
 	})
 
-	ts := httptest.NewServer(router.muxRouter)
+	ts := httptest.NewServer(router)
 	defer ts.Close()
 
 	resp, err := http.Get(ts.URL + "/stars")
@@ -51,7 +48,7 @@ func TestDocServer(t *testing.T) {
 	defer resp.Body.Close()
 	assert.Equal(t, expectedHandlerOutput, string(respBytes))
 
-	doc, err := router.docRoot.MarshalJSON()
+	doc, err := router.OpenAPIJSON()
 	require.NoError(t, err)
 	fmt.Println(string(doc))
 
@@ -106,7 +103,7 @@ func TestMiddlewares(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	ts := httptest.NewServer(router.muxRouter)
+	ts := httptest.NewServer(router)
 	defer ts.Close()
 
 	t.Run("first route", func(t *testing.T) {
@@ -132,3 +129,76 @@ func TestMiddlewares(t *testing.T) {
 	})
 
 }
+
+func TestRouteParamsValidation(t *testing.T) {
+	noop := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	type StarParameters struct {
+		StarID int `docrouter:"name:starId; kind:path"`
+	}
+	type DuplicateParameters struct {
+		StarID int    `docrouter:"name:starId; kind:path"`
+		Query  string `docrouter:"name:starId; kind:query"`
+	}
+	type DuplicateHeaders struct {
+		Color      string `docrouter:"name:X-Color; kind:header"`
+		OtherColor string `docrouter:"name:x-color; kind:header"`
+	}
+	type UnnamedParameters struct {
+		StarID int `docrouter:"kind:query"`
+	}
+	type KindlessParameters struct {
+		Limit int `docrouter:"name:limit"`
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		parameters interface{}
+		valid      bool
+	}{
+		{"matching", "/stars/{starId}", &StarParameters{}, true},
+		{"matching regexp", "/stars/{starId:[0-9]+}", &StarParameters{}, true},
+		{"missing parameter", "/stars/{starId}/planets/{planetId}", &StarParameters{}, false},
+		{"missing path variable", "/stars", &StarParameters{}, false},
+		{"no parameters", "/stars/{starId}", nil, false},
+		{"variable used twice", "/stars/{starId}/{starId}", &StarParameters{}, false},
+		{"invalid regexp", "/stars/{starId:[0-9}", &StarParameters{}, false},
+		{"duplicate across locations", "/stars/{starId}", &DuplicateParameters{}, false},
+		{"duplicate header", "/stars", &DuplicateHeaders{}, false},
+		{"unnamed parameter", "/stars", &UnnamedParameters{}, false},
+		{"parameter without kind", "/stars", &KindlessParameters{}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := New(DefaultOptions).AddRoute(Route{
+				Path:       test.path,
+				Methods:    []string{http.MethodGet},
+				Parameters: test.parameters,
+				Summary:    "Get star",
+				Handler:    noop,
+			})
+			if test.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+
+	t.Run("regexp is documented as pattern", func(t *testing.T) {
+		router := New(DefaultOptions)
+		require.NoError(t, router.AddRoute(Route{
+			Path:       "/stars/{starId:[0-9]+}",
+			Methods:    []string{http.MethodGet},
+			Parameters: &StarParameters{},
+			Summary:    "Get star",
+			Handler:    noop,
+		}))
+		pathItem := router.OpenAPI().Paths["/stars/{starId}"]
+		require.NotNil(t, pathItem)
+		param := pathItem.Get.Parameters.GetByInAndName("path", "starId")
+		require.NotNil(t, param)
+		assert.Equal(t, "^[0-9]+$", param.Schema.Value.Pattern)
+	})
+}
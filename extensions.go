@@ -0,0 +1,62 @@
+package docrouter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const extensionPrefix = "x-"
+
+func validateExtensions(extensions map[string]interface{}) error {
+	for name := range extensions {
+		if !strings.HasPrefix(name, extensionPrefix) {
+			return fmt.Errorf("extension %q must start with %q", name, extensionPrefix)
+		}
+	}
+	return nil
+}
+
+// copyExtensions copies extensions, so documents don't share maps with options and routes.
+func copyExtensions(extensions map[string]interface{}) map[string]interface{} {
+	if len(extensions) == 0 {
+		return nil
+	}
+	c := make(map[string]interface{}, len(extensions))
+	for name, value := range extensions {
+		c[name] = value
+	}
+	return c
+}
+
+// tagExtensions returns x- keys of docrouter tag. Values are decoded as JSON
+// when possible, so `x-internal: true` is a boolean and `x-owner: stars` a string.
+func tagExtensions(rawTag string) map[string]interface{} {
+	var extensions map[string]interface{}
+	for _, pair := range strings.Split(rawTag, ";") {
+		kv := strings.SplitN(pair, ":", 2)
+		name := strings.TrimSpace(kv[0])
+		if len(kv) != 2 || !strings.HasPrefix(name, extensionPrefix) {
+			continue
+		}
+		rawValue := strings.TrimSpace(kv[1])
+		var value interface{}
+		if err := json.Unmarshal([]byte(rawValue), &value); err != nil {
+			value = rawValue
+		}
+		if extensions == nil {
+			extensions = map[string]interface{}{}
+		}
+		extensions[name] = value
+	}
+	return extensions
+}
+
+func (d *ExternalDocsDoc) openAPI3() *openapi3.ExternalDocs {
+	if d == nil {
+		return nil
+	}
+	return &openapi3.ExternalDocs{Description: d.Description, URL: d.URL}
+}
@@ -0,0 +1,84 @@
+package docrouter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInfoAndExtensions(t *testing.T) {
+	opts := DefaultOptions
+	opts.Description = "Catalog of *stars*."
+	opts.TermsOfService = "https://example.com/terms"
+	opts.Contact = &ContactDoc{Name: "Stars team", Email: "stars@example.com"}
+	opts.License = &LicenseDoc{Name: "MIT", URL: "https://opensource.org/licenses/MIT"}
+	opts.ExternalDocs = &ExternalDocsDoc{URL: "https://example.com/docs"}
+	opts.Tags = []TagDoc{
+		{Name: "stars", Description: "Stars and their planets"},
+		{Name: "admin", ExternalDocs: &ExternalDocsDoc{Description: "Runbook", URL: "https://example.com/runbook"}},
+	}
+	opts.Extensions = map[string]interface{}{"x-audience": "partners"}
+	router := New(opts)
+
+	type params struct {
+		StarID int    `docrouter:"name: starId; kind: path; desc: Star identifier; example: 5; x-internal: false"`
+		Debug  string `docrouter:"name: debug; kind: header; x-kind: toggle; x-internal: true; x-owner: https://example.com/team"`
+	}
+	route := Route{
+		Path:        "/stars/{starId}",
+		Methods:     []string{http.MethodGet},
+		Parameters:  &params{},
+		Summary:     "Get star",
+		OperationID: "getStar",
+		Tags:        []string{"stars"},
+		Extensions:  map[string]interface{}{"x-rate-limit": 100},
+		Handler:     http.NotFoundHandler(),
+	}
+	require.NoError(t, router.AddRoute(route))
+
+	doc := router.OpenAPI()
+	assert.Equal(t, "Catalog of *stars*.", doc.Info.Description)
+	assert.Equal(t, "https://example.com/terms", doc.Info.TermsOfService)
+	assert.Equal(t, &openapi3.Contact{Name: "Stars team", Email: "stars@example.com"}, doc.Info.Contact)
+	assert.Equal(t, "MIT", doc.Info.License.Name)
+	assert.Equal(t, "https://example.com/docs", doc.ExternalDocs.URL)
+	require.Len(t, doc.Tags, 2)
+	assert.Equal(t, "stars", doc.Tags[0].Name)
+	assert.Equal(t, "Runbook", doc.Tags[1].ExternalDocs.Description)
+
+	op := doc.Paths["/stars/{starId}"].Get
+	assert.Equal(t, 100, op.Extensions["x-rate-limit"])
+	starID := op.Parameters.GetByInAndName("path", "starId")
+	assert.Equal(t, map[string]interface{}{"x-internal": false}, starID.Extensions)
+	debug := op.Parameters.GetByInAndName("header", "debug")
+	assert.Equal(t, map[string]interface{}{
+		"x-kind":     "toggle",
+		"x-internal": true,
+		"x-owner":    "https://example.com/team",
+	}, debug.Extensions)
+	assert.Equal(t, "header", debug.In, "x-kind doesn't shadow kind")
+
+	b, err := router.OpenAPIJSON()
+	require.NoError(t, err)
+	var encoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &encoded))
+	assert.Equal(t, "partners", encoded["x-audience"])
+	require.NoError(t, router.Validate(context.Background()))
+
+	t.Run("invalid extension names", func(t *testing.T) {
+		route := route
+		route.Path = "/other/{starId}"
+		route.Extensions = map[string]interface{}{"rate-limit": 100}
+		assert.Error(t, router.AddRoute(route))
+
+		opts := DefaultOptions
+		opts.Extensions = map[string]interface{}{"audience": "partners"}
+		issues := Lint(New(opts).OpenAPI())
+		assert.Contains(t, issues, LintIssue{LintError, "invalid-extension", "document", `extension "audience" must start with "x-"`})
+	})
+}
@@ -0,0 +1,112 @@
+package docrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrFrozen is returned when a frozen router is modified.
+var ErrFrozen = errors.New("router is frozen")
+
+// Freeze validates the router with Validate and makes it immutable.
+//
+// Before the router is frozen, requests are served under a read lock, so routes
+// can be safely added while serving. After Freeze, requests are routed without
+// any locking, the documents of the router and its API versions are serialized
+// once and parameter structs of all the routes are pre-parsed for DecodeParams
+// of the requests served by the router. AddRoute and Bind return ErrFrozen.
+//
+// Freezing a frozen router does nothing.
+func (srv *Router) Freeze() error {
+	srv.writeMu.Lock()
+	defer srv.writeMu.Unlock()
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.isFrozen() {
+		return nil
+	}
+
+	if err := srv.compile(srv.current()); err != nil {
+		return err
+	}
+	atomic.StoreInt32(&srv.frozen, 1)
+	return nil
+}
+
+// compile validates the state, serializes its documents
+// and pre-parses parameter structs of its routes.
+func (srv *Router) compile(st *routerState) error {
+	if err := srv.validate(context.Background(), st); err != nil {
+		return err
+	}
+	specJSON, err := st.marshalDoc(srv.opts.SpecVersion)
+	if err != nil {
+		return err
+	}
+	var versionJSON map[string][]byte
+	if v := srv.opts.Versioning; v != nil {
+		versionJSON = map[string][]byte{}
+		for _, version := range v.Versions {
+			vst, err := srv.versionState(st, version.Name)
+			if err != nil {
+				return err
+			}
+			if versionJSON[version.Name], err = vst.marshalDoc(srv.opts.SpecVersion); err != nil {
+				return err
+			}
+		}
+	}
+	for _, route := range st.routes {
+		if route.ParametersType == nil || route.ParametersType.Kind() != reflect.Ptr {
+			continue
+		}
+		// parsed parameters are cached for DecodeParams
+		if _, err := parseParameter(reflect.New(route.ParametersType.Elem()).Interface()); err != nil {
+			return fmt.Errorf("parse parameters of %q: %w", route.OperationID, err)
+		}
+	}
+	st.specJSON = specJSON
+	st.versionJSON = versionJSON
+	return nil
+}
+
+func (srv *Router) isFrozen() bool {
+	return atomic.LoadInt32(&srv.frozen) == 1
+}
+
+// readLock locks the router for reading unless it's frozen.
+// It returns function releasing the lock.
+func (srv *Router) readLock() func() {
+	if srv.isFrozen() {
+		return func() {}
+	}
+	srv.mu.RLock()
+	return srv.mu.RUnlock
+}
+
+type lockReleaseKey struct{}
+
+// lockRelease releases read lock taken by ServeHTTP exactly once.
+type lockRelease struct {
+	once   sync.Once
+	unlock func()
+}
+
+func (l *lockRelease) release() {
+	l.once.Do(l.unlock)
+}
+
+// releaseReadLock is mux middleware releasing the read lock after a route is matched.
+func releaseReadLock(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if l, ok := r.Context().Value(lockReleaseKey{}).(*lockRelease); ok {
+			l.release()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
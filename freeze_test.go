@@ -0,0 +1,202 @@
+package docrouter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFreeze(t *testing.T) {
+	router := New(DefaultOptions)
+
+	type StarParameters struct {
+		StarID int `docrouter:"name:starId; kind:path"`
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params StarParameters
+		if err := DecodeParams(&params, r); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// handlers can use the router while serving
+		u, err := router.URL("star-0", &params)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, u.String())
+	})
+	addRoute := func(i int) error {
+		return router.AddRoute(Route{
+			Path:        fmt.Sprintf("/stars-%d/{starId}", i),
+			Methods:     []string{http.MethodGet},
+			Parameters:  &StarParameters{},
+			Summary:     "Get star",
+			OperationID: fmt.Sprintf("star-%d", i),
+			Handler:     handler,
+		})
+	}
+	require.NoError(t, addRoute(0))
+
+	serve := func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stars-0/5", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "/stars-0/5", w.Body.String())
+	}
+
+	t.Run("add routes while serving", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 1; i <= 20; i++ {
+			wg.Add(3)
+			go func(i int) {
+				defer wg.Done()
+				assert.NoError(t, addRoute(i))
+			}(i)
+			go func() {
+				defer wg.Done()
+				serve(t)
+			}()
+			go func() {
+				defer wg.Done()
+				_, err := router.OpenAPIJSON()
+				assert.NoError(t, err)
+				router.Routes()
+				// the returned document isn't modified by routes added later
+				_, err = json.Marshal(router.OpenAPI())
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+		assert.Len(t, router.Routes(), 21)
+	})
+
+	require.NoError(t, router.Freeze())
+	require.NoError(t, router.Freeze(), "freezing twice is no-op")
+
+	t.Run("frozen", func(t *testing.T) {
+		assert.Equal(t, ErrFrozen, addRoute(100))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				serve(t)
+			}()
+			go func() {
+				defer wg.Done()
+				b, err := router.OpenAPIJSON()
+				assert.NoError(t, err)
+				assert.Contains(t, string(b), "/stars-20/{starId}")
+				assert.NoError(t, router.Validate(context.Background()))
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func TestFreezeInvalid(t *testing.T) {
+	router := New(DefaultOptions)
+	for _, path := range []string{"/stars", "/planets"} {
+		require.NoError(t, router.AddRoute(Route{
+			Path:    path,
+			Methods: []string{http.MethodGet},
+			Summary: "List",
+			Handler: http.NotFoundHandler(),
+		}))
+	}
+	assert.Error(t, router.Freeze())
+
+	// the router stays mutable
+	assert.NoError(t, router.AddRoute(Route{
+		Path:    "/moons",
+		Methods: []string{http.MethodGet},
+		Summary: "List moons",
+		Handler: http.NotFoundHandler(),
+	}))
+}
+
+func TestFreezeMultiMethodRoute(t *testing.T) {
+	type Thing struct {
+		Name string `json:"name"`
+	}
+	type ThingParameters struct {
+		ThingID int `docrouter:"name: thingId; kind: path"`
+	}
+	router := New(DefaultOptions)
+	require.NoError(t, router.AddRoute(Route{
+		Path:         "/things/{thingId}",
+		Methods:      []string{http.MethodGet, http.MethodPut},
+		Summary:      "A thing",
+		Description:  "Reads or replaces a thing.",
+		ResponseBody: Thing{},
+		Parameters:   &ThingParameters{},
+		Handler:      http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	}))
+
+	doc := router.OpenAPI()
+	assert.Equal(t, "a-thing-get", doc.Paths["/things/{thingId}"].Get.OperationID)
+	assert.Equal(t, "a-thing-put", doc.Paths["/things/{thingId}"].Put.OperationID)
+	u, err := router.URL("a-thing-put", &ThingParameters{ThingID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "/things/1", u.String(), "documented operation ID identifies the route")
+
+	err = router.AddRoute(Route{
+		Path:        "/things",
+		Methods:     []string{http.MethodGet, http.MethodPost},
+		Summary:     "Things",
+		OperationID: "things",
+		Handler:     http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	})
+	assert.Error(t, err, "explicit operation ID can't be shared by methods")
+	require.NoError(t, router.Validate(context.Background()))
+	require.NoError(t, router.Freeze())
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/things/1", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestFreezeDecoders(t *testing.T) {
+	type frozenParams struct {
+		StarID int `docrouter:"name: starId; kind: path"`
+	}
+	opts := DefaultOptions
+	opts.Versioning = &Versioning{Versions: []APIVersion{{Name: "v1"}, {Name: "v2"}}}
+	router := New(opts)
+	require.NoError(t, router.AddRoute(Route{
+		Path:       "/stars/{starId}",
+		Methods:    []string{http.MethodGet},
+		Parameters: &frozenParams{},
+		Summary:    "Get star",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var params frozenParams
+			if err := DecodeParams(&params, r); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, params.StarID)
+		}),
+	}))
+	require.NoError(t, router.Freeze())
+
+	_, parsed := parsedParameters.Load(reflect.TypeOf(frozenParams{}))
+	assert.True(t, parsed, "parameters are parsed by Freeze")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v2/stars/7", nil))
+	assert.Equal(t, "7", w.Body.String())
+
+	require.Contains(t, router.current().versionJSON, "v1")
+	v1, err := router.OpenAPIJSONForVersion("v1")
+	require.NoError(t, err)
+	assert.Equal(t, router.current().versionJSON["v1"], v1)
+}
@@ -5,8 +5,9 @@ go 1.16
 require (
 	github.com/antihax/optional v1.0.0 // indirect
 	github.com/getkin/kin-openapi v0.66.0
-	github.com/go-ozzo/ozzo-validation v3.6.0+incompatible // indirect
+	github.com/ghodss/yaml v1.0.0
+	github.com/go-ozzo/ozzo-validation v3.6.0+incompatible
 	github.com/gorilla/mux v1.8.0
-	github.com/justinas/alice v1.2.0 // indirect
+	github.com/justinas/alice v1.2.0
 	github.com/stretchr/testify v1.7.0
 )
@@ -0,0 +1,124 @@
+package docrouter
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// Labels of metrics recorded by MetricsObserver.
+const (
+	LabelOperation = "operation"
+	LabelMethod    = "method"
+	LabelRoute     = "route"
+	LabelStatus    = "status"
+)
+
+// MetricLabels are label names of request metrics of MetricsObserver.
+var MetricLabels = []string{LabelOperation, LabelMethod, LabelRoute, LabelStatus}
+
+// MetricsObserver records Prometheus-style metrics labelled by route template,
+// so their cardinality doesn't grow with path parameters. Callbacks are optional,
+// with Prometheus client they are one-liners:
+//
+//	requests := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "http_requests_total"}, docrouter.MetricLabels)
+//	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "http_request_duration_seconds"}, docrouter.MetricLabels)
+//	opts.Observer = &docrouter.MetricsObserver{
+//		CountRequest:    func(labels map[string]string) { requests.With(labels).Inc() },
+//		ObserveDuration: func(labels map[string]string, seconds float64) { duration.With(labels).Observe(seconds) },
+//	}
+type MetricsObserver struct {
+	// CountRequest increments counter of served requests, labels are MetricLabels
+	CountRequest func(labels map[string]string)
+	// ObserveDuration observes request latency in seconds, labels are MetricLabels
+	ObserveDuration func(labels map[string]string, seconds float64)
+	// ObserveResponseSize observes size of response body in bytes, labels are MetricLabels
+	ObserveResponseSize func(labels map[string]string, bytes float64)
+	// CountDecodeErrors adds number of decode errors of the request, labels are MetricLabels
+	CountDecodeErrors func(labels map[string]string, errors float64)
+	// AddInFlight adds to gauge of requests being served, labels are MetricLabels without status
+	AddInFlight func(labels map[string]string, delta float64)
+}
+
+func (m *MetricsObserver) OnRequestStart(ctx context.Context, info RequestInfo) context.Context {
+	if m.AddInFlight != nil {
+		m.AddInFlight(requestLabels(info), 1)
+	}
+	return ctx
+}
+
+func (m *MetricsObserver) OnRequestEnd(ctx context.Context, info RequestInfo, result RequestResult) {
+	if m.AddInFlight != nil {
+		m.AddInFlight(requestLabels(info), -1)
+	}
+	labels := requestLabels(info)
+	labels[LabelStatus] = strconv.Itoa(result.Status)
+	if m.CountRequest != nil {
+		m.CountRequest(labels)
+	}
+	if m.ObserveDuration != nil {
+		m.ObserveDuration(labels, result.Latency.Seconds())
+	}
+	if m.ObserveResponseSize != nil {
+		m.ObserveResponseSize(labels, float64(result.BytesWritten))
+	}
+	if m.CountDecodeErrors != nil && len(result.DecodeErrors) > 0 {
+		m.CountDecodeErrors(labels, float64(len(result.DecodeErrors)))
+	}
+}
+
+func requestLabels(info RequestInfo) map[string]string {
+	return map[string]string{
+		LabelOperation: info.OperationID,
+		LabelMethod:    info.Method,
+		LabelRoute:     info.PathTemplate,
+	}
+}
+
+// Span is a tracing span started by TracingObserver.
+type Span interface {
+	SetAttributes(attributes map[string]interface{})
+	RecordError(err error)
+	// SetError sets status of the span to error
+	SetError(description string)
+	End()
+}
+
+// TracingObserver starts a span for every request, named and attributed by
+// OpenTelemetry HTTP semantic conventions, e.g. "GET /stars/{starId}".
+// StartSpan of OpenTelemetry adapts tracer.Start and wraps trace.Span to Span.
+type TracingObserver struct {
+	// StartSpan starts server span as a child of span in ctx
+	StartSpan func(ctx context.Context, name string) (context.Context, Span)
+}
+
+type spanKey struct{}
+
+func (t *TracingObserver) OnRequestStart(ctx context.Context, info RequestInfo) context.Context {
+	ctx, span := t.StartSpan(ctx, info.Method+" "+info.PathTemplate)
+	span.SetAttributes(map[string]interface{}{
+		"http.request.method":  info.Method,
+		"http.route":           info.PathTemplate,
+		"url.path":             info.Request.URL.Path,
+		"openapi.operation_id": info.OperationID,
+	})
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+func (t *TracingObserver) OnRequestEnd(ctx context.Context, info RequestInfo, result RequestResult) {
+	span, ok := ctx.Value(spanKey{}).(Span)
+	if !ok {
+		return
+	}
+	span.SetAttributes(map[string]interface{}{
+		"http.response.status_code": result.Status,
+		"http.response.body.size":   result.BytesWritten,
+	})
+	for _, err := range result.DecodeErrors {
+		span.RecordError(err)
+	}
+	if result.Status >= http.StatusInternalServerError {
+		span.SetError(http.StatusText(result.Status))
+	}
+	span.End()
+}
@@ -0,0 +1,86 @@
+// Package loader runs code against a *docrouter.Router constructed by a user package.
+//
+// Go can't load packages at runtime, so the loader writes a small main program
+// importing the user package, and runs it with the go tool from the current
+// module, which must be able to resolve both the user package and docrouter.
+package loader
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"text/template"
+)
+
+// Program describes code run against the router.
+type Program struct {
+	// PkgPath is import path of the package with the router constructor.
+	PkgPath string
+	// Constructor is name of an exported func() *docrouter.Router in the package.
+	Constructor string
+	// Imports are additional import paths needed by Body.
+	Imports []string
+	// Body declares func run(router *docrouter.Router, args []string) error.
+	Body string
+}
+
+// Run runs the program with args and returns its standard output.
+// Standard error of a successful run is forwarded to os.Stderr.
+func Run(p Program, args ...string) ([]byte, error) {
+	var src bytes.Buffer
+	if err := mainTemplate.Execute(&src, p); err != nil {
+		return nil, fmt.Errorf("execute template: %v", err)
+	}
+
+	dir, err := ioutil.TempDir("", "docrouter-loader")
+	if err != nil {
+		return nil, fmt.Errorf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	mainFile := filepath.Join(dir, "main.go")
+	if err := ioutil.WriteFile(mainFile, src.Bytes(), 0o600); err != nil {
+		return nil, fmt.Errorf("write main file: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("go", append([]string{"run", mainFile}, args...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("go run: %v: %s", err, stderr.String())
+	}
+	os.Stderr.Write(stderr.Bytes())
+	return stdout.Bytes(), nil
+}
+
+var mainTemplate = template.Must(template.New("main").Parse(`// Code generated by docrouter loader. DO NOT EDIT.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/zdebra/docrouter"
+{{- range .Imports}}
+	"{{.}}"
+{{- end}}
+
+	target "{{.PkgPath}}"
+)
+
+var _ *docrouter.Router
+
+func main() {
+	router := target.{{.Constructor}}()
+	if err := run(router, os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+{{.Body}}
+`))
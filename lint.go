@@ -0,0 +1,134 @@
+package docrouter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type LintSeverity string
+
+const (
+	LintError   LintSeverity = "error"
+	LintWarning LintSeverity = "warning"
+)
+
+// LintIssue is a problem found in OpenAPI document by Lint.
+type LintIssue struct {
+	Severity LintSeverity
+	// Rule identifies the lint rule, e.g. "duplicate-operation-id"
+	Rule string
+	// Location in the document, e.g. "GET /stars/{starId}"
+	Location string
+	Message  string
+}
+
+func (i LintIssue) String() string {
+	return fmt.Sprintf("%s: %s: %s (%s)", i.Severity, i.Location, i.Message, i.Rule)
+}
+
+// Lint checks OpenAPI document for problems which are valid OpenAPI,
+// but make the documentation incomplete or ambiguous for its consumers.
+// Issues are ordered by path and method.
+func Lint(doc *openapi3.T) []LintIssue {
+	issues := []LintIssue{}
+	operationIDs := map[string]string{}
+
+	if err := validateExtensions(doc.Extensions); err != nil {
+		issues = append(issues, LintIssue{Severity: LintError, Rule: "invalid-extension", Location: "document", Message: err.Error()})
+	}
+
+	for _, path := range sortedPaths(doc.Paths) {
+		pathItem := doc.Paths[path]
+		for _, method := range sortedMethods(pathItem) {
+			operation := pathItem.GetOperation(method)
+			location := method + " " + path
+			issue := func(severity LintSeverity, rule, format string, args ...interface{}) {
+				issues = append(issues, LintIssue{
+					Severity: severity,
+					Rule:     rule,
+					Location: location,
+					Message:  fmt.Sprintf(format, args...),
+				})
+			}
+
+			switch prev, found := operationIDs[operation.OperationID]; {
+			case operation.OperationID == "":
+				issue(LintWarning, "missing-operation-id", "operation has no operationId")
+			case found:
+				issue(LintError, "duplicate-operation-id", "operationId %q is already used by %s", operation.OperationID, prev)
+			default:
+				operationIDs[operation.OperationID] = location
+			}
+
+			if operation.Description == "" {
+				issue(LintWarning, "missing-description", "operation has no description")
+			}
+			if err := validateExtensions(operation.Extensions); err != nil {
+				issue(LintError, "invalid-extension", "%v", err)
+			}
+			if !hasDocumentedResponse(operation.Responses) {
+				issue(LintWarning, "undocumented-responses", "operation has no documented responses")
+			}
+
+			for _, paramRef := range operation.Parameters {
+				param := paramRef.Value
+				if param == nil {
+					continue
+				}
+				if param.Description == "" {
+					issue(LintWarning, "missing-description", "%s parameter %q has no description", param.In, param.Name)
+				}
+				if !hasParameterExample(param) {
+					issue(LintWarning, "missing-example", "%s parameter %q has no example", param.In, param.Name)
+				}
+				if err := validateExtensions(param.Extensions); err != nil {
+					issue(LintError, "invalid-extension", "%s parameter %q: %v", param.In, param.Name, err)
+				}
+			}
+		}
+	}
+	return issues
+}
+
+func hasDocumentedResponse(responses openapi3.Responses) bool {
+	for _, response := range responses {
+		if response.Ref != "" {
+			return true
+		}
+		if response.Value != nil && response.Value.Description != nil && *response.Value.Description != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func hasParameterExample(param *openapi3.Parameter) bool {
+	if param.Example != nil && param.Example != "" {
+		return true
+	}
+	if len(param.Examples) > 0 {
+		return true
+	}
+	return param.Schema != nil && param.Schema.Value != nil && param.Schema.Value.Example != nil
+}
+
+func sortedPaths(paths openapi3.Paths) []string {
+	keys := make([]string, 0, len(paths))
+	for path := range paths {
+		keys = append(keys, path)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedMethods(pathItem *openapi3.PathItem) []string {
+	methods := []string{}
+	for method := range pathItem.Operations() {
+		methods = append(methods, strings.ToUpper(method))
+	}
+	sort.Strings(methods)
+	return methods
+}
@@ -0,0 +1,46 @@
+package docrouter
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	router := New(DefaultOptions)
+
+	type StarParameters struct {
+		StarID int    `docrouter:"name:starId; kind:path; desc:Star identifier; example:5"`
+		Color  string `docrouter:"name:color; kind:query"`
+	}
+
+	noop := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	require.NoError(t, router.AddRoute(Route{
+		Path:        "/stars/{starId}",
+		Methods:     []string{http.MethodGet},
+		Parameters:  &StarParameters{},
+		Summary:     "Get star",
+		Description: "Returns a star.",
+		OperationID: "star",
+		Handler:     noop,
+	}))
+	require.NoError(t, router.AddRoute(Route{
+		Path:        "/stars",
+		Methods:     []string{http.MethodGet},
+		Summary:     "List stars",
+		OperationID: "star",
+		Handler:     noop,
+	}))
+
+	issues := Lint(router.OpenAPI())
+	assert.Equal(t, []LintIssue{
+		{LintWarning, "missing-description", "GET /stars", "operation has no description"},
+		{LintWarning, "undocumented-responses", "GET /stars", "operation has no documented responses"},
+		{LintError, "duplicate-operation-id", "GET /stars/{starId}", `operationId "star" is already used by GET /stars`},
+		{LintWarning, "undocumented-responses", "GET /stars/{starId}", "operation has no documented responses"},
+		{LintWarning, "missing-description", "GET /stars/{starId}", `query parameter "color" has no description`},
+		{LintWarning, "missing-example", "GET /stars/{starId}", `query parameter "color" has no example`},
+	}, issues)
+}
@@ -0,0 +1,183 @@
+package docrouter
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Observer is notified about requests served by routes, e.g. to record metrics
+// or tracing spans. Requests not matching any route aren't observed.
+type Observer interface {
+	// OnRequestStart is called before the route middlewares and handler.
+	// The returned context is passed to the handler, e.g. with a started span.
+	OnRequestStart(ctx context.Context, info RequestInfo) context.Context
+	// OnRequestEnd is called with context returned by OnRequestStart
+	// after the handler returns.
+	OnRequestEnd(ctx context.Context, info RequestInfo, result RequestResult)
+}
+
+// RequestInfo identifies the route serving a request.
+type RequestInfo struct {
+	OperationID string
+	// PathTemplate is the path as registered, e.g. /stars/{starId}. Unlike
+	// request path, it's usable as a low cardinality label.
+	PathTemplate string
+	Method       string
+	Request      *http.Request
+}
+
+// RequestResult describes served request.
+type RequestResult struct {
+	Status int
+	// BytesWritten is the size of response body as written by the handler
+	BytesWritten int64
+	Latency      time.Duration
+	// DecodeErrors are errors of DecodeParams, DecodeBody
+	// and request validation of routers created with NewFromSpec
+	DecodeErrors []error
+	// Panicked reports that the handler panicked and the panic wasn't
+	// recovered by Options.Recover. Status is 500 then.
+	Panicked bool
+}
+
+// Observers combines observers, they are called in the given order.
+func Observers(observers ...Observer) Observer {
+	return multiObserver(observers)
+}
+
+type multiObserver []Observer
+
+func (m multiObserver) OnRequestStart(ctx context.Context, info RequestInfo) context.Context {
+	for _, o := range m {
+		ctx = o.OnRequestStart(ctx, info)
+	}
+	return ctx
+}
+
+func (m multiObserver) OnRequestEnd(ctx context.Context, info RequestInfo, result RequestResult) {
+	for i := len(m) - 1; i >= 0; i-- {
+		m[i].OnRequestEnd(ctx, info, result)
+	}
+}
+
+type decodeErrorsKey struct{}
+
+// decodeErrors collects errors of decoding a request.
+type decodeErrors struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+// reportDecodeError records decode error for the observer of the request.
+func reportDecodeError(ctx context.Context, err error) {
+	if collected, ok := ctx.Value(decodeErrorsKey{}).(*decodeErrors); ok {
+		collected.mu.Lock()
+		collected.errs = append(collected.errs, err)
+		collected.mu.Unlock()
+	}
+}
+
+func (srv *Router) observerMiddleware(route *Route, pathTemplate string) func(http.Handler) http.Handler {
+	observer := srv.opts.Observer
+	operationID := uniqueOperationID(route)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			info := RequestInfo{
+				OperationID:  operationID,
+				PathTemplate: pathTemplate,
+				Method:       r.Method,
+				Request:      r,
+			}
+			collected := &decodeErrors{}
+			ctx := context.WithValue(r.Context(), decodeErrorsKey{}, collected)
+			ctx = observer.OnRequestStart(ctx, info)
+
+			sw := &statusWriter{ResponseWriter: w}
+			// panics aren't recovered here, so they keep their stack
+			completed := false
+			defer func() {
+				collected.mu.Lock()
+				errs := collected.errs
+				collected.mu.Unlock()
+				result := RequestResult{
+					Status:       sw.statusCode(),
+					BytesWritten: sw.written,
+					Latency:      time.Since(start),
+					DecodeErrors: errs,
+				}
+				if !completed {
+					result.Status = http.StatusInternalServerError
+					result.Panicked = true
+				}
+				observer.OnRequestEnd(ctx, info, result)
+			}()
+			next.ServeHTTP(sw, r.WithContext(ctx))
+			completed = true
+		})
+	}
+}
+
+// statusWriter records status and size of the response.
+type statusWriter struct {
+	http.ResponseWriter
+	status  int
+	written int64
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.written += int64(n)
+	return n, err
+}
+
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack is used e.g. by websocket upgrades, hijacked connection
+// is recorded as 101 Switching Protocols.
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := hijack(w.ResponseWriter)
+	if err == nil && w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// Unwrap is used by http.ResponseController.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// hijack takes over connection of the wrapped response writer.
+func hijack(w http.ResponseWriter) (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	return h.Hijack()
+}
+
+func (w *statusWriter) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
@@ -0,0 +1,181 @@
+package docrouter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSpan struct {
+	name       string
+	attributes map[string]interface{}
+	errors     []error
+	status     string
+	ended      bool
+}
+
+func (s *testSpan) SetAttributes(attributes map[string]interface{}) {
+	for k, v := range attributes {
+		s.attributes[k] = v
+	}
+}
+func (s *testSpan) RecordError(err error)       { s.errors = append(s.errors, err) }
+func (s *testSpan) SetError(description string) { s.status = description }
+func (s *testSpan) End()                        { s.ended = true }
+
+func TestObserver(t *testing.T) {
+	var mu sync.Mutex
+	counts := map[string]int{}
+	inFlight := 0.0
+	var decodeErrors float64
+	metrics := &MetricsObserver{
+		CountRequest: func(labels map[string]string) {
+			mu.Lock()
+			defer mu.Unlock()
+			counts[labels[LabelOperation]+" "+labels[LabelMethod]+" "+labels[LabelRoute]+" "+labels[LabelStatus]]++
+		},
+		AddInFlight: func(labels map[string]string, delta float64) {
+			mu.Lock()
+			defer mu.Unlock()
+			inFlight += delta
+		},
+		CountDecodeErrors: func(labels map[string]string, errors float64) {
+			mu.Lock()
+			defer mu.Unlock()
+			decodeErrors += errors
+		},
+	}
+	spans := []*testSpan{}
+	tracing := &TracingObserver{
+		StartSpan: func(ctx context.Context, name string) (context.Context, Span) {
+			span := &testSpan{name: name, attributes: map[string]interface{}{}}
+			spans = append(spans, span)
+			return ctx, span
+		},
+	}
+
+	opts := DefaultOptions
+	opts.Observer = Observers(metrics, tracing)
+	router := New(opts)
+
+	type params struct {
+		StarID int `docrouter:"name: starId; kind: path"`
+	}
+	require.NoError(t, router.AddRoute(Route{
+		Path:        "/stars/{starId}",
+		Methods:     []string{http.MethodGet},
+		Parameters:  &params{},
+		Summary:     "Get star",
+		OperationID: "getStar",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var p params
+			if err := DecodeParams(&p, r); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if p.StarID == 0 {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.Write([]byte("star"))
+		}),
+	}))
+
+	for _, path := range []string{"/stars/1", "/stars/2", "/stars/abc", "/stars/0", "/planets"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, map[string]int{
+		"getStar GET /stars/{starId} 200": 2,
+		"getStar GET /stars/{starId} 400": 1,
+		"getStar GET /stars/{starId} 500": 1,
+	}, counts, "unmatched requests aren't observed")
+	assert.Equal(t, 0.0, inFlight)
+	assert.Equal(t, 1.0, decodeErrors)
+
+	require.Len(t, spans, 4)
+	assert.Equal(t, "GET /stars/{starId}", spans[0].name)
+	assert.Equal(t, map[string]interface{}{
+		"http.request.method":       "GET",
+		"http.route":                "/stars/{starId}",
+		"url.path":                  "/stars/1",
+		"openapi.operation_id":      "getStar",
+		"http.response.status_code": 200,
+		"http.response.body.size":   int64(4),
+	}, spans[0].attributes)
+	assert.True(t, spans[0].ended)
+	assert.Len(t, spans[2].errors, 1, "decode error is recorded")
+	assert.Equal(t, "Internal Server Error", spans[3].status)
+}
+
+func TestObserverPanic(t *testing.T) {
+	var results []RequestResult
+	opts := DefaultOptions
+	opts.Observer = observerFunc(func(result RequestResult) {
+		results = append(results, result)
+	})
+	router := New(opts)
+	require.NoError(t, router.AddRoute(Route{
+		Path:    "/boom",
+		Methods: []string{http.MethodGet},
+		Summary: "Boom",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}),
+	}))
+
+	assert.PanicsWithValue(t, "boom", func() {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/boom", nil))
+	})
+	require.Len(t, results, 1)
+	assert.Equal(t, http.StatusInternalServerError, results[0].Status)
+	assert.True(t, results[0].Panicked)
+}
+
+// observerFunc observes results of requests.
+type observerFunc func(result RequestResult)
+
+func (f observerFunc) OnRequestStart(ctx context.Context, info RequestInfo) context.Context {
+	return ctx
+}
+
+func (f observerFunc) OnRequestEnd(ctx context.Context, info RequestInfo, result RequestResult) {
+	f(result)
+}
+
+func TestObserverHijack(t *testing.T) {
+	results := make(chan RequestResult, 1)
+	opts := DefaultOptions
+	opts.Observer = observerFunc(func(result RequestResult) { results <- result })
+	router := New(opts)
+	require.NoError(t, router.AddRoute(Route{
+		Path:    "/socket",
+		Methods: []string{http.MethodGet},
+		Summary: "Socket",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			conn, rw, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			defer conn.Close()
+			_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: star\r\nConnection: Upgrade\r\n\r\n")
+			_ = rw.Flush()
+		}),
+	}))
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/socket", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	assert.Equal(t, http.StatusSwitchingProtocols, (<-results).Status)
+}
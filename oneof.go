@@ -0,0 +1,413 @@
+package docrouter
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// oneOf describes concrete types of an interface type.
+type oneOf struct {
+	discriminator string
+	// variants maps discriminator values to concrete types
+	variants map[string]reflect.Type
+}
+
+var (
+	oneOfTypesMu sync.RWMutex
+	oneOfTypes   = map[reflect.Type]*oneOf{}
+)
+
+// RegisterOneOf registers concrete types of an interface type used in request
+// or response bodies. Reflected schemas of the interface emit oneOf with
+// discriminator and DecodeBody decodes the interface into the concrete type
+// selected by the discriminator property.
+//
+// The iface is a nil pointer to the interface type and variants map discriminator
+// values to values of the concrete types:
+//
+//	docrouter.RegisterOneOf((*Shape)(nil), "type", map[string]interface{}{
+//		"circle": Circle{},
+//		"square": &Square{},
+//	})
+//
+// The concrete types are responsible for encoding the discriminator property.
+// Like gob.Register, it's meant to be called from init functions.
+func RegisterOneOf(iface interface{}, discriminator string, variants map[string]interface{}) error {
+	ifacePtr := reflect.TypeOf(iface)
+	if ifacePtr == nil || ifacePtr.Kind() != reflect.Ptr || ifacePtr.Elem().Kind() != reflect.Interface {
+		return fmt.Errorf("iface must be a nil pointer to an interface, got %T", iface)
+	}
+	ifaceType := ifacePtr.Elem()
+	if discriminator == "" {
+		return fmt.Errorf("discriminator of %s is empty", ifaceType)
+	}
+	if len(variants) == 0 {
+		return fmt.Errorf("no variants of %s", ifaceType)
+	}
+
+	o := &oneOf{
+		discriminator: discriminator,
+		variants:      map[string]reflect.Type{},
+	}
+	for value, variant := range variants {
+		t := reflect.TypeOf(variant)
+		if t == nil || !t.Implements(ifaceType) {
+			return fmt.Errorf("variant %q of type %T doesn't implement %s", value, variant, ifaceType)
+		}
+		if elem := indirectType(t); elem.Kind() != reflect.Struct || elem.Name() == "" {
+			return fmt.Errorf("variant %q of type %T must be a named struct", value, variant)
+		}
+		o.variants[value] = t
+	}
+
+	oneOfTypesMu.Lock()
+	defer oneOfTypesMu.Unlock()
+	if _, found := oneOfTypes[ifaceType]; found {
+		return fmt.Errorf("variants of %s are already registered", ifaceType)
+	}
+	oneOfTypes[ifaceType] = o
+	return nil
+}
+
+func lookupOneOf(t reflect.Type) *oneOf {
+	if t.Kind() != reflect.Interface {
+		return nil
+	}
+	oneOfTypesMu.RLock()
+	defer oneOfTypesMu.RUnlock()
+	return oneOfTypes[t]
+}
+
+func (o *oneOf) sortedValues() []string {
+	values := make([]string, 0, len(o.variants))
+	for value := range o.variants {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return values
+}
+
+// oneOfSchema creates schema of registered interface type.
+// Variants are always components, as discriminator mapping needs references.
+func (g *schemaGenerator) oneOfSchema(o *oneOf) *openapi3.Schema {
+	schema := &openapi3.Schema{
+		Discriminator: &openapi3.Discriminator{
+			PropertyName: o.discriminator,
+			Mapping:      map[string]string{},
+		},
+	}
+	for _, value := range o.sortedValues() {
+		t := indirectType(o.variants[value])
+		ref := g.schemaRef(t)
+		if rt := g.types[t]; rt != nil {
+			g.promote(t, rt, "")
+			addDiscriminatorProperty(rt.schema, o.discriminator, value)
+		}
+		schema.OneOf = append(schema.OneOf, ref)
+		schema.Discriminator.Mapping[value] = ref.Ref
+	}
+	return schema
+}
+
+// addDiscriminatorProperty documents the discriminator property of variant
+// unless the variant struct has a field for it.
+func addDiscriminatorProperty(schema *openapi3.Schema, discriminator, value string) {
+	if schema.Properties == nil {
+		schema.Properties = openapi3.Schemas{}
+	}
+	if _, found := schema.Properties[discriminator]; !found {
+		schema.Properties[discriminator] = openapi3.NewStringSchema().WithEnum(value).NewRef()
+	}
+	for _, required := range schema.Required {
+		if required == discriminator {
+			return
+		}
+	}
+	schema.Required = append(schema.Required, discriminator)
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// containsOneOf reports whether decoding the type needs to dispatch registered interfaces.
+func containsOneOf(t reflect.Type, visited map[reflect.Type]bool) bool {
+	if visited[t] {
+		return false
+	}
+	visited[t] = true
+	if t.Kind() != reflect.Interface && reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+		// custom unmarshalers are left to encoding/json
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Interface:
+		return lookupOneOf(t) != nil
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return containsOneOf(t.Elem(), visited)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if containsOneOf(t.Field(i).Type, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// unmarshalJSON works like json.Unmarshal, but decodes registered interfaces
+// into their concrete types. The v must be settable.
+func unmarshalJSON(data []byte, v reflect.Value) error {
+	t := v.Type()
+	if !containsOneOf(t, map[reflect.Type]bool{}) {
+		return json.Unmarshal(data, v.Addr().Interface())
+	}
+	if string(data) == "null" {
+		v.Set(reflect.Zero(t))
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return unmarshalOneOf(data, v, lookupOneOf(t))
+	case reflect.Ptr:
+		elem := reflect.New(t.Elem())
+		if err := unmarshalJSON(data, elem.Elem()); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.Slice:
+		var raws []json.RawMessage
+		if err := json.Unmarshal(data, &raws); err != nil {
+			return err
+		}
+		slice := reflect.MakeSlice(t, len(raws), len(raws))
+		for i, raw := range raws {
+			if err := unmarshalJSON(raw, slice.Index(i)); err != nil {
+				return fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+		v.Set(slice)
+	case reflect.Array:
+		var raws []json.RawMessage
+		if err := json.Unmarshal(data, &raws); err != nil {
+			return err
+		}
+		for i := 0; i < t.Len() && i < len(raws); i++ {
+			if err := unmarshalJSON(raws[i], v.Index(i)); err != nil {
+				return fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return fmt.Errorf("unsupported map key %s", t.Key())
+		}
+		var raws map[string]json.RawMessage
+		if err := json.Unmarshal(data, &raws); err != nil {
+			return err
+		}
+		m := reflect.MakeMapWithSize(t, len(raws))
+		for key, raw := range raws {
+			elem := reflect.New(t.Elem()).Elem()
+			if err := unmarshalJSON(raw, elem); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			m.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), elem)
+		}
+		v.Set(m)
+	case reflect.Struct:
+		return unmarshalStruct(data, v)
+	default:
+		return json.Unmarshal(data, v.Addr().Interface())
+	}
+	return nil
+}
+
+func unmarshalOneOf(data []byte, v reflect.Value, o *oneOf) error {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return err
+	}
+	var value string
+	if raw, found := probe[o.discriminator]; found {
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return fmt.Errorf("discriminator %q: %v", o.discriminator, err)
+		}
+	}
+	variantType, found := o.variants[value]
+	if !found {
+		return fmt.Errorf("unknown %q value %q of %s", o.discriminator, value, v.Type())
+	}
+	variant := reflect.New(variantType).Elem()
+	if err := unmarshalJSON(data, variant); err != nil {
+		return err
+	}
+	v.Set(variant)
+	return nil
+}
+
+// jsonField is a field of a struct as seen by encoding/json,
+// fields of embedded structs are promoted.
+type jsonField struct {
+	name   string
+	index  []int
+	tagged bool
+	// quoted is set by the ",string" option
+	quoted bool
+}
+
+// jsonFields lists fields of the struct type decoded by encoding/json.
+// Promoted fields follow its dominance rules: the shallowest field wins,
+// a tagged one wins among fields of the same depth, other conflicts are ignored.
+func jsonFields(t reflect.Type) []jsonField {
+	var all []jsonField
+	var walk func(t reflect.Type, index []int, visited map[reflect.Type]bool)
+	walk = func(t reflect.Type, index []int, visited map[reflect.Type]bool) {
+		if visited[t] {
+			return
+		}
+		visited[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			tag := field.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, _, _ := jsonFieldName(field)
+			fieldIndex := append(append([]int{}, index...), i)
+			if field.Anonymous {
+				ft := indirectType(field.Type)
+				if name == "" && ft.Kind() == reflect.Struct {
+					walk(ft, fieldIndex, visited)
+					continue
+				}
+				if field.PkgPath != "" && ft.Kind() != reflect.Struct {
+					continue
+				}
+			} else if field.PkgPath != "" {
+				continue
+			}
+			f := jsonField{name: name, index: fieldIndex, tagged: name != ""}
+			if name == "" {
+				f.name = field.Name
+			}
+			ft := field.Type
+			if ft.Name() == "" && ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			switch ft.Kind() {
+			case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+				reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+				f.quoted = containsString(strings.Split(tag, ",")[1:], "string")
+			}
+			all = append(all, f)
+		}
+	}
+	walk(t, nil, map[reflect.Type]bool{})
+
+	byName := map[string][]jsonField{}
+	names := []string{}
+	for _, f := range all {
+		if _, found := byName[f.name]; !found {
+			names = append(names, f.name)
+		}
+		byName[f.name] = append(byName[f.name], f)
+	}
+	fields := []jsonField{}
+	for _, name := range names {
+		if f, ok := dominantField(byName[name]); ok {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+func dominantField(fields []jsonField) (jsonField, bool) {
+	depth := len(fields[0].index)
+	for _, f := range fields {
+		if len(f.index) < depth {
+			depth = len(f.index)
+		}
+	}
+	var dominant []jsonField
+	tagged := 0
+	for _, f := range fields {
+		if len(f.index) == depth {
+			dominant = append(dominant, f)
+			if f.tagged {
+				tagged++
+			}
+		}
+	}
+	if len(dominant) == 1 {
+		return dominant[0], true
+	}
+	if tagged == 1 {
+		for _, f := range dominant {
+			if f.tagged {
+				return f, true
+			}
+		}
+	}
+	return jsonField{}, false
+}
+
+// unmarshalStruct decodes JSON object into struct v as encoding/json does.
+func unmarshalStruct(data []byte, v reflect.Value) error {
+	var raws map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return err
+	}
+	for _, field := range jsonFields(v.Type()) {
+		raw, found := raws[field.name]
+		if !found {
+			for key, r := range raws {
+				if strings.EqualFold(key, field.name) {
+					raw, found = r, true
+					break
+				}
+			}
+		}
+		if !found {
+			continue
+		}
+		fv, ok := fieldByIndex(v, field.index)
+		if !ok {
+			continue
+		}
+		if field.quoted && string(raw) != "null" {
+			var quoted string
+			if err := json.Unmarshal(raw, &quoted); err != nil {
+				return fmt.Errorf("%s: %w", field.name, err)
+			}
+			raw = json.RawMessage(quoted)
+		}
+		if err := unmarshalJSON(raw, fv); err != nil {
+			return fmt.Errorf("%s: %w", field.name, err)
+		}
+	}
+	return nil
+}
+
+// fieldByIndex returns the nested field, nil embedded struct pointers are allocated.
+// It fails for nil pointers to unexported structs, which can't be allocated.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, v.CanSet()
+}
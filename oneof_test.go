@@ -0,0 +1,202 @@
+package docrouter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type oneOfTestShape interface {
+	area() float64
+}
+
+type oneOfTestCircle struct {
+	Kind   string  `json:"kind"`
+	Radius float64 `json:"radius"`
+}
+
+func (c oneOfTestCircle) area() float64 { return 3 * c.Radius * c.Radius }
+
+type oneOfTestSquare struct {
+	Side float64 `json:"side"`
+}
+
+func (s *oneOfTestSquare) area() float64 { return s.Side * s.Side }
+
+type oneOfTestDrawing struct {
+	Title  string           `json:"title"`
+	Shapes []oneOfTestShape `json:"shapes"`
+	Main   oneOfTestShape   `json:"main"`
+}
+
+// oneOfTestNamedShape is decoded from the name of the shape.
+type oneOfTestNamedShape struct {
+	Shape oneOfTestShape
+}
+
+func (s *oneOfTestNamedShape) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	s.Shape = oneOfTestCircle{Kind: name}
+	return nil
+}
+
+// OneOfTestLayer is exported, so it can be allocated when embedded as pointer.
+type OneOfTestLayer struct {
+	Main oneOfTestShape `json:"main"`
+}
+
+func init() {
+	err := RegisterOneOf((*oneOfTestShape)(nil), "kind", map[string]interface{}{
+		"circle": oneOfTestCircle{},
+		"square": &oneOfTestSquare{},
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestRegisterOneOf(t *testing.T) {
+	err := RegisterOneOf((*oneOfTestShape)(nil), "kind", map[string]interface{}{"circle": oneOfTestCircle{}})
+	assert.Error(t, err, "already registered")
+
+	err = RegisterOneOf(oneOfTestCircle{}, "kind", map[string]interface{}{"circle": oneOfTestCircle{}})
+	assert.Error(t, err, "not an interface")
+
+	type other interface{ other() }
+	err = RegisterOneOf((*other)(nil), "kind", map[string]interface{}{"circle": oneOfTestCircle{}})
+	assert.Error(t, err, "variant doesn't implement the interface")
+
+	err = RegisterOneOf((*other)(nil), "", map[string]interface{}{})
+	assert.Error(t, err, "empty discriminator")
+}
+
+func TestOneOfSchema(t *testing.T) {
+	components := openapi3.NewComponents()
+	g := newSchemaGenerator(&components)
+
+	drawing := g.forRoute(0).schemaRef(reflect.TypeOf(oneOfTestDrawing{})).Value
+	shape := drawing.Properties["main"].Value
+	require.NotNil(t, shape.Discriminator)
+	assert.Equal(t, "kind", shape.Discriminator.PropertyName)
+	assert.Equal(t, map[string]string{
+		"circle": "#/components/schemas/oneOfTestCircle",
+		"square": "#/components/schemas/oneOfTestSquare",
+	}, shape.Discriminator.Mapping)
+	require.Len(t, shape.OneOf, 2)
+	assert.Equal(t, "#/components/schemas/oneOfTestCircle", shape.OneOf[0].Ref)
+	assert.Equal(t, "#/components/schemas/oneOfTestSquare", drawing.Properties["shapes"].Value.Items.Value.OneOf[1].Ref)
+
+	circle := components.Schemas["oneOfTestCircle"].Value
+	assert.Equal(t, "string", circle.Properties["kind"].Value.Type)
+	assert.Nil(t, circle.Properties["kind"].Value.Enum, "existing field is kept")
+
+	square := components.Schemas["oneOfTestSquare"].Value
+	assert.Equal(t, []interface{}{"square"}, square.Properties["kind"].Value.Enum, "discriminator is documented")
+	assert.Contains(t, square.Required, "kind")
+
+	doc := &openapi3.T{OpenAPI: "3.0.3", Info: &openapi3.Info{Title: "t", Version: "1"}, Paths: openapi3.Paths{}, Components: components}
+	assert.NoError(t, doc.Validate(context.Background()))
+}
+
+func TestDecodeBody(t *testing.T) {
+	body := `{
+		"title": "shapes",
+		"main": {"kind": "square", "side": 2},
+		"shapes": [{"kind": "circle", "radius": 1}, {"kind": "square", "side": 3}]
+	}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+	var drawing oneOfTestDrawing
+	require.NoError(t, DecodeBody(req, &drawing))
+	assert.Equal(t, "shapes", drawing.Title)
+	assert.Equal(t, &oneOfTestSquare{Side: 2}, drawing.Main)
+	assert.Equal(t, []oneOfTestShape{
+		oneOfTestCircle{Kind: "circle", Radius: 1},
+		&oneOfTestSquare{Side: 3},
+	}, drawing.Shapes)
+
+	t.Run("top-level interface", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"kind": "circle", "radius": 2}`))
+		var shape oneOfTestShape
+		require.NoError(t, DecodeBody(req, &shape))
+		assert.Equal(t, 12.0, shape.area())
+	})
+
+	t.Run("unknown discriminator", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"main": {"kind": "triangle"}}`))
+		err := DecodeBody(req, &oneOfTestDrawing{})
+		assert.EqualError(t, err, `decode body: main: unknown "kind" value "triangle" of docrouter.oneOfTestShape`)
+	})
+
+	t.Run("embedded pointer", func(t *testing.T) {
+		type Drawing struct {
+			*OneOfTestLayer
+			Title string `json:"title"`
+		}
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"title": "x", "main": {"kind": "square", "side": 2}}`))
+		var drawing Drawing
+		require.NoError(t, DecodeBody(req, &drawing))
+		assert.Equal(t, "x", drawing.Title)
+		require.NotNil(t, drawing.OneOfTestLayer)
+		assert.Equal(t, &oneOfTestSquare{Side: 2}, drawing.Main)
+
+		req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"title": "x"}`))
+		drawing = Drawing{}
+		require.NoError(t, DecodeBody(req, &drawing))
+		assert.Nil(t, drawing.OneOfTestLayer, "allocated only when its fields are present")
+	})
+
+	t.Run("string option", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"id": "7", "main": {"kind": "square", "side": 1}}`))
+		var v struct {
+			ID   int            `json:"id,string"`
+			Main oneOfTestShape `json:"main"`
+		}
+		require.NoError(t, DecodeBody(req, &v))
+		assert.Equal(t, 7, v.ID)
+		assert.Equal(t, &oneOfTestSquare{Side: 1}, v.Main)
+	})
+
+	t.Run("custom unmarshaler", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`"circle"`))
+		var v oneOfTestNamedShape
+		require.NoError(t, DecodeBody(req, &v))
+		assert.Equal(t, oneOfTestCircle{Kind: "circle"}, v.Shape)
+	})
+
+	t.Run("dominant field", func(t *testing.T) {
+		type Inner struct {
+			Title string         `json:"title"`
+			Main  oneOfTestShape `json:"main"`
+		}
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"title": "outer", "main": {"kind": "square", "side": 1}}`))
+		var v struct {
+			Inner
+			Title string `json:"title"`
+		}
+		require.NoError(t, DecodeBody(req, &v))
+		assert.Equal(t, "outer", v.Title)
+		assert.Empty(t, v.Inner.Title, "shadowed by the outer field")
+		assert.Equal(t, &oneOfTestSquare{Side: 1}, v.Main)
+	})
+
+	t.Run("plain types", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name": "x"}`))
+		var v struct {
+			Name string `json:"name"`
+		}
+		require.NoError(t, DecodeBody(req, &v))
+		assert.Equal(t, "x", v.Name)
+	})
+}
@@ -1,24 +1,98 @@
 package docrouter
 
+import "net/http"
+
 type Options struct {
 	Title   string
 	Version string
+	// Description of the API. Should use CommonMark syntax
+	Description    string
+	TermsOfService string
+	Contact        *ContactDoc
+	License        *LicenseDoc
+	ExternalDocs   *ExternalDocsDoc
+	// Tags describe tags used by routes, their order is kept in the document
+	Tags []TagDoc
+	// Extensions are vendor extensions of the document, their names must start with "x-"
+	Extensions map[string]interface{}
+
+	// Servers are documented in OpenAPI schema. They don't have any effect
+	// on request matching unless ServerMatching is set.
+	Servers        []ServerDoc
+	ServerMatching ServerMatching
+
+	// AutoOptions answers OPTIONS requests for every registered path
+	// with an Allow header listing the methods of the routes on that path.
+	AutoOptions bool
+	// AutoHead serves HEAD requests for every route accepting GET.
+	AutoHead bool
+	// CORS enables centrally handled CORS preflights and response headers.
+	// Preflight requests are answered even if AutoOptions is disabled.
+	CORS *CORSOptions
+
+	// ValidateOnServe runs Router.Validate before the first request is served
+	// and again after the routes change. When the validation fails, requests
+	// are answered with 500 and the error is logged.
+	ValidateOnServe bool
+	// StrictValidation makes Router.Validate fail on lint warnings too.
+	StrictValidation bool
 
-	// ServerURLs are used purely for generating OpenAPI schema.
-	// It doesn't have any effect on a request host matching.
-	Servers []ServerDoc
+	// Compression compresses responses of routes, see CompressionOptions.
+	Compression *CompressionOptions
+	// Recover answers requests whose handlers panic with 500 problem details
+	// response, see RecoverOptions. The response is documented for every route.
+	Recover *RecoverOptions
+
+	// Versioning serves several API versions, see Versioning.
+	Versioning *Versioning
+	// Observer is notified about requests served by routes, see MetricsObserver and TracingObserver.
+	Observer Observer
+	// AccessLog logs requests served by routes. Parameters tagged with
+	// `sensitive: true` are redacted.
+	AccessLog AccessLogger
+	// OnDeprecatedUse is called when a deprecated operation, parameter or API version is used,
+	// e.g. to find out who still calls them before their removal.
+	OnDeprecatedUse func(r *http.Request, use DeprecatedUse)
+
+	// SpecVersion is OpenAPI version of the encoded documents, OpenAPI30 or OpenAPI31.
+	// Empty value means OpenAPI30.
+	SpecVersion string
 }
 
 type ServerDoc struct {
+	// URL may contain variables in braces, e.g. https://{region}.example.com/v3
 	URL         string
 	Description string
+	Variables   map[string]ServerVariableDoc
+}
+
+type ContactDoc struct {
+	Name  string
+	URL   string
+	Email string
+}
+
+type LicenseDoc struct {
+	Name string
+	URL  string
+}
+
+type ExternalDocsDoc struct {
+	Description string
+	URL         string
+}
+
+type TagDoc struct {
+	Name         string
+	Description  string
+	ExternalDocs *ExternalDocsDoc
 }
 
 var DefaultOptions = Options{
 	Title:   "Default Title",
 	Version: "1.0",
 	Servers: []ServerDoc{
-		{"https://www.example.com/v3", "Production environment API"},
-		{"https://test.example.com/v3", "Test environment API"},
+		{URL: "https://www.example.com/v3", Description: "Production environment API"},
+		{URL: "https://test.example.com/v3", Description: "Test environment API"},
 	},
 }
@@ -3,6 +3,7 @@ package docrouter
 import (
 	"fmt"
 	"reflect"
+	"sync"
 )
 
 type parsedParameter struct {
@@ -12,16 +13,24 @@ type parsedParameter struct {
 type taggedField struct {
 	name               string
 	kind               reflect.Kind
+	typ                reflect.Type
 	rawTag             string
 	parsedDocrouterTag map[string]string // "desc": "xxxx", "example": "3"
+	extensions         map[string]interface{}
 }
 
+// parsedParameters caches parsed parameters by struct type
+var parsedParameters sync.Map // reflect.Type -> parsedParameter
+
 func parseParameter(structPtr interface{}) (parsedParameter, error) {
 	var pParam parsedParameter
 	v := reflect.ValueOf(structPtr).Elem()
 	if !v.CanAddr() {
 		return pParam, fmt.Errorf("item must be a pointer")
 	}
+	if cached, found := parsedParameters.Load(v.Type()); found {
+		return cached.(parsedParameter), nil
+	}
 
 	pParam.fields = []taggedField{}
 	for i := 0; i < v.NumField(); i++ {
@@ -39,6 +48,8 @@ func parseParameter(structPtr interface{}) (parsedParameter, error) {
 			"example",
 			"required",
 			"schemaMin",
+			"deprecated",
+			"sensitive",
 		}
 		parsedDocrouterTag := map[string]string{}
 		for _, key := range keys {
@@ -47,11 +58,14 @@ func parseParameter(structPtr interface{}) (parsedParameter, error) {
 		pParam.fields = append(pParam.fields, taggedField{
 			name:               fieldName,
 			kind:               typeField.Type.Kind(),
+			typ:                typeField.Type,
 			rawTag:             docrouterTag,
 			parsedDocrouterTag: parsedDocrouterTag,
+			extensions:         tagExtensions(docrouterTag),
 		})
 
 	}
+	parsedParameters.Store(v.Type(), pParam)
 	return pParam, nil
 }
 
@@ -67,6 +81,14 @@ func (tf *taggedField) getTagSchemaMin() string {
 	return tf.parsedDocrouterTag["schemaMin"]
 }
 
+func (tf *taggedField) getTagDeprecated() string {
+	return tf.parsedDocrouterTag["deprecated"]
+}
+
+func (tf *taggedField) getTagSensitive() string {
+	return tf.parsedDocrouterTag["sensitive"]
+}
+
 func (tf *taggedField) getTagName() string {
 	return tf.parsedDocrouterTag["name"]
 }
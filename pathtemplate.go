@@ -0,0 +1,86 @@
+package docrouter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// pathVariable is a variable of gorilla/mux path template like {id} or {id:[0-9]+}.
+type pathVariable struct {
+	name string
+	// pattern is the regular expression of the variable, empty for default
+	pattern string
+}
+
+// parsePathTemplate returns variables of gorilla/mux path template in order of appearance.
+func parsePathTemplate(tpl string) ([]pathVariable, error) {
+	vars := []pathVariable{}
+	level, start := 0, 0
+	for i := 0; i < len(tpl); i++ {
+		switch tpl[i] {
+		case '{':
+			if level == 0 {
+				start = i
+			}
+			level++
+		case '}':
+			level--
+			if level < 0 {
+				return nil, fmt.Errorf("unbalanced braces in path %q", tpl)
+			}
+			if level == 0 {
+				v, err := parsePathVariable(tpl[start+1 : i])
+				if err != nil {
+					return nil, fmt.Errorf("path %q: %w", tpl, err)
+				}
+				vars = append(vars, v)
+			}
+		}
+	}
+	if level != 0 {
+		return nil, fmt.Errorf("unbalanced braces in path %q", tpl)
+	}
+	return vars, nil
+}
+
+func parsePathVariable(raw string) (pathVariable, error) {
+	v := pathVariable{name: raw}
+	if idx := strings.Index(raw, ":"); idx != -1 {
+		v.name, v.pattern = raw[:idx], raw[idx+1:]
+		if _, err := regexp.Compile(v.pattern); err != nil {
+			return v, fmt.Errorf("invalid pattern of variable %q: %v", v.name, err)
+		}
+	}
+	if v.name == "" {
+		return v, fmt.Errorf("variable with empty name")
+	}
+	return v, nil
+}
+
+// openAPIPattern converts mux variable pattern, which must match the whole
+// path segment, to OpenAPI schema pattern.
+func (v pathVariable) openAPIPattern() string {
+	if v.pattern == "" {
+		return ""
+	}
+	if strings.Contains(v.pattern, "|") {
+		return "^(?:" + v.pattern + ")$"
+	}
+	return "^" + v.pattern + "$"
+}
+
+// openAPIPath converts gorilla/mux path template to OpenAPI path template
+// by removing variable patterns, e.g. /stars/{id:[0-9]+} becomes /stars/{id}.
+func openAPIPath(tpl string) (string, error) {
+	vars, err := parsePathTemplate(tpl)
+	if err != nil {
+		return "", err
+	}
+	for _, v := range vars {
+		if v.pattern != "" {
+			tpl = strings.Replace(tpl, "{"+v.name+":"+v.pattern+"}", "{"+v.name+"}", 1)
+		}
+	}
+	return tpl, nil
+}
@@ -0,0 +1,29 @@
+package docrouter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePathTemplate(t *testing.T) {
+	vars, err := parsePathTemplate("/galaxies/{galaxy}/stars/{starId:[0-9]{1,3}}")
+	require.NoError(t, err)
+	assert.Equal(t, []pathVariable{
+		{name: "galaxy"},
+		{name: "starId", pattern: "[0-9]{1,3}"},
+	}, vars)
+
+	path, err := openAPIPath("/galaxies/{galaxy}/stars/{starId:[0-9]{1,3}}")
+	require.NoError(t, err)
+	assert.Equal(t, "/galaxies/{galaxy}/stars/{starId}", path)
+
+	assert.Equal(t, "^[0-9]{1,3}$", vars[1].openAPIPattern())
+	assert.Equal(t, "^(?:red|blue)$", pathVariable{name: "color", pattern: "red|blue"}.openAPIPattern())
+
+	for _, invalid := range []string{"/stars/{id", "/stars/id}", "/stars/{}", "/stars/{id:[0-9}"} {
+		_, err := parsePathTemplate(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,251 @@
+package docrouter
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// RateLimit limits requests to a route with a token bucket per key.
+//
+// The bucket holds Burst tokens and is refilled with Requests tokens
+// every Interval. Every request takes a token, requests finding the bucket
+// empty are answered with 429. Limits are documented with the 429 response.
+type RateLimit struct {
+	Requests int
+	Interval time.Duration
+	// Burst is size of the bucket, Requests when zero.
+	Burst int
+	// Key identifies the client, ClientIPKey when nil. Requests with the same
+	// key share the bucket, including requests with empty key.
+	// It's called after route middlewares, so it can use e.g. principal
+	// stored in the request context by an authentication middleware.
+	Key func(r *http.Request) string
+	// Store keeps the buckets, the router keeps them in memory when nil.
+	// Requests are served when the store fails.
+	Store RateLimitStore
+	// OnStoreError is called when Store fails, the error is logged when nil.
+	OnStoreError func(r *http.Request, err error)
+}
+
+// RateLimitStore keeps token buckets of rate limits, so the limits can be
+// shared by several instances of a service.
+type RateLimitStore interface {
+	// Take takes a token from the bucket of the key.
+	Take(ctx context.Context, key string, limit RateLimit) (RateLimitResult, error)
+}
+
+// RateLimitResult is state of a bucket after taking a token.
+type RateLimitResult struct {
+	Allowed   bool
+	Remaining int
+	// RetryAfter is time until the next token is available, set for requests not allowed.
+	RetryAfter time.Duration
+	// Reset is time until the bucket is full again.
+	Reset time.Duration
+}
+
+// ClientIPKey identifies client by IP address of the connection.
+func ClientIPKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// HeaderKey identifies client by value of the request header, e.g. API key.
+func HeaderKey(name string) func(r *http.Request) string {
+	return func(r *http.Request) string {
+		return r.Header.Get(name)
+	}
+}
+
+func (l *RateLimit) validate() error {
+	if l.Requests <= 0 {
+		return fmt.Errorf("rate limit requests must be positive")
+	}
+	if l.Interval <= 0 {
+		return fmt.Errorf("rate limit interval must be positive")
+	}
+	if l.Burst < 0 {
+		return fmt.Errorf("rate limit burst must not be negative")
+	}
+	return nil
+}
+
+func (l *RateLimit) burst() int {
+	if l.Burst == 0 {
+		return l.Requests
+	}
+	return l.Burst
+}
+
+// perSecond is rate of refilling the bucket in tokens per second.
+func (l *RateLimit) perSecond() float64 {
+	return float64(l.Requests) / l.Interval.Seconds()
+}
+
+func (l *RateLimit) key(r *http.Request) string {
+	if l.Key == nil {
+		return ClientIPKey(r)
+	}
+	return l.Key(r)
+}
+
+// rateLimitMiddleware answers requests exceeding the limit with 429,
+// buckets of the route are prefixed with its operation ID.
+func (srv *Router) rateLimitMiddleware(route *Route) func(http.Handler) http.Handler {
+	limit := *route.RateLimit
+	store := limit.Store
+	if store == nil {
+		store = srv.memoryRateLimits()
+	}
+	prefix := uniqueOperationID(route) + ":"
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			result, err := store.Take(r.Context(), prefix+limit.key(r), limit)
+			if err != nil {
+				if limit.OnStoreError != nil {
+					limit.OnStoreError(r, err)
+				} else {
+					log.Printf("docrouter: rate limit of %s %s: %v", r.Method, r.URL.Path, err)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(limit.burst()))
+			h.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+			if !result.Allowed {
+				h.Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+				writeProblem(w, http.StatusTooManyRequests, "rate limit exceeded")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// memoryRateLimits returns store shared by routes without their own store.
+func (srv *Router) memoryRateLimits() RateLimitStore {
+	if srv.rateLimits == nil {
+		srv.rateLimits = NewMemoryRateLimitStore()
+	}
+	return srv.rateLimits
+}
+
+// rateLimitResponse documents response of requests exceeding the limit.
+func rateLimitResponse(responses openapi3.Responses) {
+	if _, found := responses["429"]; found {
+		return
+	}
+	response := problemResponse("Too many requests")
+	response.Value.Headers = openapi3.Headers{
+		"Retry-After":         rateLimitHeader("Seconds until the next request is allowed"),
+		"RateLimit-Limit":     rateLimitHeader("Maximum number of requests in a burst"),
+		"RateLimit-Remaining": rateLimitHeader("Number of requests left in the current burst"),
+		"RateLimit-Reset":     rateLimitHeader("Seconds until the limit is fully restored"),
+	}
+	responses["429"] = response
+}
+
+func rateLimitHeader(description string) *openapi3.HeaderRef {
+	return &openapi3.HeaderRef{
+		Value: &openapi3.Header{
+			Parameter: openapi3.Parameter{
+				Description: description,
+				Schema:      openapi3.NewIntegerSchema().WithMin(0).NewRef(),
+			},
+		},
+	}
+}
+
+// MemoryRateLimitStore keeps token buckets in memory of the process.
+type MemoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	// takes counts takes since full buckets were removed
+	takes int
+	now   func() time.Time
+}
+
+// memoryStoreSweep is number of takes after which full buckets are removed.
+const memoryStoreSweep = 1024
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+	// burst and rate of the last take, used for removing full buckets
+	burst, rate float64
+}
+
+func (b *tokenBucket) refill(now time.Time) float64 {
+	return math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets: map[string]*tokenBucket{},
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryRateLimitStore) Take(ctx context.Context, key string, limit RateLimit) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	burst, rate := float64(limit.burst()), limit.perSecond()
+
+	s.takes++
+	if s.takes >= memoryStoreSweep {
+		s.sweep(now)
+	}
+
+	b, found := s.buckets[key]
+	if !found {
+		b = &tokenBucket{tokens: burst, last: now}
+		s.buckets[key] = b
+	}
+	b.burst, b.rate = burst, rate
+	b.tokens = b.refill(now)
+	b.last = now
+
+	result := RateLimitResult{}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsDuration((1 - b.tokens) / rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = secondsDuration((burst - b.tokens) / rate)
+	return result, nil
+}
+
+// sweep removes buckets which are full again.
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	s.takes = 0
+	for key, b := range s.buckets {
+		if b.refill(now) >= b.burst {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func secondsDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,132 @@
+package docrouter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	router := New(DefaultOptions)
+	require.NoError(t, router.AddRoute(Route{
+		Path:      "/stars",
+		Methods:   []string{http.MethodGet},
+		Summary:   "List stars",
+		RateLimit: &RateLimit{Requests: 2, Interval: time.Minute},
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("stars"))
+		}),
+	}))
+
+	get := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/stars", nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("10.0.0.1:1234")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", w.Header().Get("RateLimit-Reset"))
+
+	assert.Equal(t, http.StatusOK, get("10.0.0.1:1235").Code)
+	w = get("10.0.0.1:1236")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, problemMediaType, w.Header().Get("Content-Type"))
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusOK, get("10.0.0.2:1234").Code, "other clients have their own bucket")
+
+	response := router.OpenAPI().Paths["/stars"].Get.Responses.Get(http.StatusTooManyRequests)
+	require.NotNil(t, response)
+	assert.Contains(t, response.Value.Headers, "Retry-After")
+	assert.Contains(t, response.Value.Headers, "RateLimit-Remaining")
+	require.NoError(t, router.Validate(context.Background()))
+}
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Take(context.Context, string, RateLimit) (RateLimitResult, error) {
+	return RateLimitResult{}, errors.New("store is down")
+}
+
+func TestRateLimitStore(t *testing.T) {
+	var storeErrs []error
+	router := New(DefaultOptions)
+	require.NoError(t, router.AddRoute(Route{
+		Path:    "/stars",
+		Methods: []string{http.MethodGet},
+		Summary: "List stars",
+		RateLimit: &RateLimit{
+			Requests: 1,
+			Interval: time.Minute,
+			Key:      HeaderKey("X-API-Key"),
+			Store:    failingRateLimitStore{},
+			OnStoreError: func(r *http.Request, err error) {
+				storeErrs = append(storeErrs, err)
+			},
+		},
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	}))
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stars", nil))
+		assert.Equal(t, http.StatusOK, w.Code, "requests are served when the store fails")
+	}
+	assert.Len(t, storeErrs, 2)
+}
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(0, 0)
+	store := NewMemoryRateLimitStore()
+	store.now = func() time.Time { return now }
+	limit := RateLimit{Requests: 1, Interval: time.Second, Burst: 3}
+
+	for i := 2; i >= 0; i-- {
+		result, err := store.Take(ctx, "a", limit)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, i, result.Remaining)
+	}
+	result, err := store.Take(ctx, "a", limit)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+	assert.Equal(t, 3*time.Second, result.Reset)
+
+	now = now.Add(1500 * time.Millisecond)
+	result, err = store.Take(ctx, "a", limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed, "bucket is refilled")
+	assert.Equal(t, 0, result.Remaining)
+
+	now = now.Add(time.Hour)
+	store.takes = memoryStoreSweep
+	_, err = store.Take(ctx, "b", limit)
+	require.NoError(t, err)
+	assert.NotContains(t, store.buckets, "a", "full buckets are removed")
+}
+
+func TestRateLimitInvalid(t *testing.T) {
+	router := New(DefaultOptions)
+	err := router.AddRoute(Route{
+		Path:      "/stars",
+		Methods:   []string{http.MethodGet},
+		Summary:   "List stars",
+		RateLimit: &RateLimit{Requests: 1},
+		Handler:   http.NotFoundHandler(),
+	})
+	assert.Error(t, err)
+}
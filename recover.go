@@ -0,0 +1,109 @@
+package docrouter
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// problemMediaType is media type of problem details responses, see RFC 7807.
+const problemMediaType = "application/problem+json"
+
+// RecoverOptions configures recovery from handler panics, see Options.Recover.
+type RecoverOptions struct {
+	// OnPanic is called with the recovered value and stack of the panicking goroutine.
+	// The stack is written to the standard logger when nil.
+	OnPanic func(r *http.Request, recovered interface{}, stack []byte)
+}
+
+// problem is body of problem details responses.
+type problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+func writeProblem(w http.ResponseWriter, status int, detail string) {
+	w.Header().Set("Content-Type", problemMediaType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	})
+}
+
+// problemResponse documents problem details response.
+func problemResponse(description string) *openapi3.ResponseRef {
+	schema := openapi3.NewObjectSchema().
+		WithProperty("type", openapi3.NewStringSchema()).
+		WithProperty("title", openapi3.NewStringSchema()).
+		WithProperty("status", openapi3.NewIntegerSchema()).
+		WithProperty("detail", openapi3.NewStringSchema())
+	schema.Required = []string{"type", "title", "status"}
+	return &openapi3.ResponseRef{
+		Value: openapi3.NewResponse().
+			WithDescription(description).
+			WithContent(openapi3.NewContentWithSchema(schema, []string{problemMediaType})),
+	}
+}
+
+// addProblemResponse documents the response unless the status is already documented.
+// Responses are shared by operations of a route.
+func addProblemResponse(responses openapi3.Responses, status, description string) {
+	if _, found := responses[status]; !found {
+		responses[status] = problemResponse(description)
+	}
+}
+
+func (o *RecoverOptions) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := &statusWriter{ResponseWriter: w}
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				// the server aborts the response silently
+				panic(recovered)
+			}
+			stack := debug.Stack()
+			if p, ok := recovered.(*handlerPanic); ok {
+				recovered, stack = p.value, p.stack
+			}
+			o.onPanic(r, recovered, stack)
+			if sw.status == 0 {
+				writeProblem(sw, http.StatusInternalServerError, "")
+			}
+		}()
+		next.ServeHTTP(sw, r)
+	})
+}
+
+func (o *RecoverOptions) onPanic(r *http.Request, recovered interface{}, stack []byte) {
+	if o.OnPanic != nil {
+		o.OnPanic(r, recovered, stack)
+		return
+	}
+	logPanic(r, recovered, stack)
+}
+
+// reportPanic reports panic which can't be passed to the serving goroutine.
+func (srv *Router) reportPanic(r *http.Request, recovered interface{}, stack []byte) {
+	if srv.opts.Recover != nil {
+		srv.opts.Recover.onPanic(r, recovered, stack)
+		return
+	}
+	logPanic(r, recovered, stack)
+}
+
+func logPanic(r *http.Request, recovered interface{}, stack []byte) {
+	log.Printf("docrouter: panic serving %s %s: %v\n%s", r.Method, r.URL.Path, recovered, stack)
+}
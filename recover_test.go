@@ -0,0 +1,201 @@
+package docrouter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecover(t *testing.T) {
+	var recovered interface{}
+	var stack []byte
+	opts := DefaultOptions
+	opts.Recover = &RecoverOptions{
+		OnPanic: func(r *http.Request, rec interface{}, s []byte) {
+			recovered, stack = rec, s
+		},
+	}
+	router := New(opts)
+	require.NoError(t, router.AddRoute(Route{
+		Path:    "/boom",
+		Methods: []string{http.MethodGet},
+		Summary: "Boom",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}),
+	}))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/boom", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, problemMediaType, w.Header().Get("Content-Type"))
+	var body problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, problem{Type: "about:blank", Title: "Internal Server Error", Status: 500}, body)
+	assert.Equal(t, "boom", recovered)
+	assert.Contains(t, string(stack), "recover_test.go")
+
+	doc := router.OpenAPI()
+	response := doc.Paths["/boom"].Get.Responses.Get(500)
+	require.NotNil(t, response)
+	assert.Contains(t, response.Value.Content, problemMediaType)
+	require.NoError(t, router.Validate(context.Background()))
+}
+
+func TestRecoverAfterWrite(t *testing.T) {
+	opts := DefaultOptions
+	opts.Recover = &RecoverOptions{OnPanic: func(*http.Request, interface{}, []byte) {}}
+	router := New(opts)
+	require.NoError(t, router.AddRoute(Route{
+		Path:    "/boom",
+		Methods: []string{http.MethodGet},
+		Summary: "Boom",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			panic("boom")
+		}),
+	}))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/boom", nil))
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Empty(t, w.Body.String(), "response already started isn't overwritten")
+}
+
+func TestTimeout(t *testing.T) {
+	opts := DefaultOptions
+	opts.Recover = &RecoverOptions{OnPanic: func(*http.Request, interface{}, []byte) {}}
+	router := New(opts)
+	handlerErr := make(chan error, 1)
+	require.NoError(t, router.AddRoute(Route{
+		Path:    "/slow",
+		Methods: []string{http.MethodGet},
+		Summary: "Slow",
+		Timeout: 10 * time.Millisecond,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+			// gives the router time to answer
+			time.Sleep(50 * time.Millisecond)
+			_, err := w.Write([]byte("late"))
+			handlerErr <- err
+		}),
+	}))
+	require.NoError(t, router.AddRoute(Route{
+		Path:    "/fast",
+		Methods: []string{http.MethodGet},
+		Summary: "Fast",
+		Timeout: time.Second,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Star", "sun")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte("fast"))
+		}),
+	}))
+	require.NoError(t, router.AddRoute(Route{
+		Path:    "/panic",
+		Methods: []string{http.MethodGet},
+		Summary: "Panic",
+		Timeout: time.Second,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}),
+	}))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, problemMediaType, w.Header().Get("Content-Type"))
+	assert.Equal(t, http.ErrHandlerTimeout, <-handlerErr)
+	assert.NotContains(t, w.Body.String(), "late")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "sun", w.Header().Get("X-Star"))
+	assert.Equal(t, "fast", w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code, "panic is recovered outside of the timeout")
+
+	doc := router.OpenAPI()
+	operation := doc.Paths["/slow"].Get
+	assert.Equal(t, int64(10), operation.Extensions[timeoutExtension])
+	assert.NotNil(t, operation.Responses.Get(503))
+	require.NoError(t, router.Validate(context.Background()))
+}
+
+func TestTimeoutNegative(t *testing.T) {
+	router := New(DefaultOptions)
+	err := router.AddRoute(Route{
+		Path:    "/slow",
+		Methods: []string{http.MethodGet},
+		Summary: "Slow",
+		Timeout: -time.Second,
+		Handler: http.NotFoundHandler(),
+	})
+	assert.Error(t, err)
+}
+
+func panicInStarHandler() {
+	panic("boom")
+}
+
+func TestTimeoutPanicStack(t *testing.T) {
+	type reported struct {
+		recovered interface{}
+		stack     string
+	}
+	panics := make(chan reported, 2)
+	opts := DefaultOptions
+	opts.Recover = &RecoverOptions{
+		OnPanic: func(r *http.Request, recovered interface{}, stack []byte) {
+			panics <- reported{recovered, string(stack)}
+		},
+	}
+	router := New(opts)
+	require.NoError(t, router.AddRoute(Route{
+		Path:    "/panic",
+		Methods: []string{http.MethodGet},
+		Summary: "Panic",
+		Timeout: time.Second,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panicInStarHandler()
+		}),
+	}))
+	require.NoError(t, router.AddRoute(Route{
+		Path:    "/late",
+		Methods: []string{http.MethodGet},
+		Summary: "Late panic",
+		Timeout: 10 * time.Millisecond,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+			time.Sleep(20 * time.Millisecond)
+			panicInStarHandler()
+		}),
+	}))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	p := <-panics
+	assert.Equal(t, "boom", p.recovered)
+	assert.Contains(t, p.stack, "panicInStarHandler", "stack of the panicking goroutine is reported")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/late", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	select {
+	case p := <-panics:
+		assert.Equal(t, "boom", p.recovered)
+		assert.Contains(t, p.stack, "panicInStarHandler")
+	case <-time.After(time.Second):
+		t.Fatal("panic after the timeout isn't reported")
+	}
+}
@@ -0,0 +1,67 @@
+package docrouter
+
+import (
+	"context"
+	"fmt"
+)
+
+// Replace replaces all the routes of the router.
+//
+// New handlers and document are built aside while the current routes keep
+// serving, then they are swapped atomically. In-flight requests finish with
+// the handlers they were matched to and the document always describes the
+// routes being served. If any of the routes is invalid, nothing is replaced.
+//
+// Replace works on frozen routers too, the new routes are validated as with Freeze.
+func (srv *Router) Replace(routes []Route) error {
+	srv.writeMu.Lock()
+	defer srv.writeMu.Unlock()
+	return srv.rebuild(routes)
+}
+
+// RemoveRoute removes route with the operation ID. See Replace.
+func (srv *Router) RemoveRoute(operationID string) error {
+	srv.writeMu.Lock()
+	defer srv.writeMu.Unlock()
+
+	routes := []Route{}
+	for _, route := range srv.current().added {
+		if !hasOperationID(uniqueOperationID(&route), route.Methods, operationID) {
+			routes = append(routes, route)
+		}
+	}
+	if len(routes) == len(srv.current().added) {
+		return fmt.Errorf("operation %q not found", operationID)
+	}
+	return srv.rebuild(routes)
+}
+
+// rebuild builds new state from routes and swaps it with the current one.
+// The caller must hold writeMu.
+func (srv *Router) rebuild(routes []Route) error {
+	if srv.specBindings != nil {
+		return fmt.Errorf("routes of a router created from spec are defined by the spec")
+	}
+
+	st, err := srv.newState()
+	if err != nil {
+		return err
+	}
+	for _, route := range routes {
+		if err := srv.addRoute(st, route); err != nil {
+			return fmt.Errorf("route %q: %w", route.Path, err)
+		}
+	}
+	if srv.isFrozen() {
+		if err := srv.compile(st); err != nil {
+			return err
+		}
+	} else if srv.opts.ValidateOnServe {
+		if err := srv.validate(context.Background(), st); err != nil {
+			return err
+		}
+	}
+
+	srv.state.Store(st)
+	return nil
+}
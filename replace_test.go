@@ -0,0 +1,97 @@
+package docrouter
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplace(t *testing.T) {
+	textRoute := func(path, text string) Route {
+		return Route{
+			Path:        path,
+			Methods:     []string{http.MethodGet},
+			Summary:     "Get " + text,
+			OperationID: text,
+			Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, text)
+			}),
+		}
+	}
+	get := func(router *Router, path string) (int, string) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Code, w.Body.String()
+	}
+
+	router := New(DefaultOptions)
+	require.NoError(t, router.AddRoute(textRoute("/stars", "stars")))
+	require.NoError(t, router.AddRoute(textRoute("/planets", "planets")))
+	require.NoError(t, router.Freeze())
+
+	t.Run("replace", func(t *testing.T) {
+		require.NoError(t, router.Replace([]Route{
+			textRoute("/stars", "stars-v2"),
+			textRoute("/moons", "moons"),
+		}))
+
+		code, body := get(router, "/stars")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "stars-v2", body)
+		code, _ = get(router, "/planets")
+		assert.Equal(t, http.StatusNotFound, code)
+
+		spec, err := router.OpenAPIJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(spec), "/moons")
+		assert.NotContains(t, string(spec), "/planets")
+		assert.Len(t, router.Routes(), 2)
+	})
+
+	t.Run("remove route", func(t *testing.T) {
+		require.NoError(t, router.RemoveRoute("moons"))
+		code, _ := get(router, "/moons")
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.Error(t, router.RemoveRoute("moons"))
+	})
+
+	t.Run("invalid routes are not applied", func(t *testing.T) {
+		err := router.Replace([]Route{
+			textRoute("/stars", "duplicate"),
+			textRoute("/planets", "duplicate"),
+		})
+		assert.Error(t, err)
+		_, body := get(router, "/stars")
+		assert.Equal(t, "stars-v2", body)
+	})
+
+	t.Run("add route is still rejected", func(t *testing.T) {
+		assert.Equal(t, ErrFrozen, router.AddRoute(textRoute("/comets", "comets")))
+	})
+
+	t.Run("replace while serving", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(2)
+			go func(i int) {
+				defer wg.Done()
+				assert.NoError(t, router.Replace([]Route{
+					textRoute("/stars", fmt.Sprintf("stars-%d", i)),
+				}))
+			}(i)
+			go func() {
+				defer wg.Done()
+				code, _ := get(router, "/stars")
+				assert.Equal(t, http.StatusOK, code)
+				_, err := router.OpenAPIJSON()
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	validation "github.com/go-ozzo/ozzo-validation"
@@ -28,6 +29,34 @@ type Route struct {
 	Summary string
 	// Optional description. Should use CommonMark syntax
 	Description string
+	// Optional operation identifier. Derived from Summary when empty, derived
+	// identifiers of routes with several methods are suffixed with the method
+	// in the document. It can't be set on routes with several methods.
+	OperationID string
+	// Tags group operations in the documentation
+	Tags []string
+	// Responses maps status codes to names of responses registered with Router.AddResponse
+	Responses map[string]string
+	// Deprecated marks the operation as deprecated in the document.
+	// Parameters are deprecated with `deprecated: true` in their docrouter tags.
+	Deprecated bool
+	// Deprecation is sent in response headers of deprecated route, optional.
+	// It's rejected on routes which aren't Deprecated.
+	Deprecation *Deprecation
+	// Versions the route belongs to, see Options.Versioning. All versions when empty.
+	Versions []string
+	// Timeout cancels context of requests taking longer, the client is answered with 503.
+	// It's documented in milliseconds with the x-timeout extension. No timeout when zero.
+	Timeout time.Duration
+	// RateLimit limits requests per client, optional
+	RateLimit *RateLimit
+	// Cache makes responses to GET requests cacheable, optional
+	Cache *Cache
+	// DisableCompression opts out of Options.Compression, e.g. for already compressed content
+	DisableCompression bool
+	// Extensions are vendor extensions of the operation, their names must start with "x-".
+	// Parameters take extensions from their docrouter tags, e.g. `docrouter:"...; x-internal: true"`.
+	Extensions map[string]interface{}
 }
 
 func (r *Route) openAPI3Params() (openapi3.Parameters, error) {
@@ -37,8 +66,15 @@ func (r *Route) openAPI3Params() (openapi3.Parameters, error) {
 		if err != nil {
 			return nil, fmt.Errorf("create params with reflection: %w", err)
 		}
+		pathVars, err := parsePathTemplate(r.Path)
+		if err != nil {
+			return nil, err
+		}
 
 		for _, rParam := range reflectedParams {
+			if rParam.In == openapi3.ParameterInPath {
+				setPathParamPattern(rParam, pathVars)
+			}
 			params = append(params, &openapi3.ParameterRef{
 				Value: rParam,
 			})
@@ -47,6 +83,15 @@ func (r *Route) openAPI3Params() (openapi3.Parameters, error) {
 	return params, nil
 }
 
+// setPathParamPattern translates gorilla/mux variable regexp to schema pattern.
+func setPathParamPattern(param *openapi3.Parameter, pathVars []pathVariable) {
+	for _, v := range pathVars {
+		if v.name == param.Name && v.pattern != "" && param.Schema != nil && param.Schema.Value != nil {
+			param.Schema.Value.Pattern = v.openAPIPattern()
+		}
+	}
+}
+
 func createParamsWithReflection(structPtr interface{}) ([]*openapi3.Parameter, error) {
 	pParam, err := parseParameter(structPtr)
 	if err != nil {
@@ -86,11 +131,13 @@ func createParamsWithReflection(structPtr interface{}) ([]*openapi3.Parameter, e
 			}
 			schemaType = "integer"
 		case reflect.Bool:
-			x, err := strconv.ParseBool(tField.getTagExample())
-			if err != nil {
-				return nil, fmt.Errorf("invalid bool value for field %q, tag: `example`: %v", fieldName, err)
+			if exampleStrVal := tField.getTagExample(); exampleStrVal != "" {
+				x, err := strconv.ParseBool(exampleStrVal)
+				if err != nil {
+					return nil, fmt.Errorf("invalid bool value for field %q, tag: `example`: %v", fieldName, err)
+				}
+				exampleTag = x
 			}
-			exampleTag = x
 			schemaType = "boolean"
 		default:
 			exampleTag = tField.getTagExample()
@@ -109,10 +156,43 @@ func createParamsWithReflection(structPtr interface{}) ([]*openapi3.Parameter, e
 			}
 		}
 
+		deprecated := false
+		if tField.getTagDeprecated() != "" {
+			deprecated, err = strconv.ParseBool(tField.getTagDeprecated())
+			if err != nil {
+				return nil, fmt.Errorf("invalid bool value for field %q, tag: `deprecated`: %v", fieldName, err)
+			}
+		}
+
+		extensions := copyExtensions(tField.extensions)
+		if tField.getTagSensitive() != "" {
+			sensitive, err := strconv.ParseBool(tField.getTagSensitive())
+			if err != nil {
+				return nil, fmt.Errorf("invalid bool value for field %q, tag: `sensitive`: %v", fieldName, err)
+			}
+			if sensitive {
+				if extensions == nil {
+					extensions = map[string]interface{}{}
+				}
+				extensions[sensitiveExtension] = true
+			}
+		}
+
 		schemaFromTag, err := schemaFromTag(tField.getTagSchemaMin(), schemaType)
 		if err != nil {
 			return nil, fmt.Errorf("schemaFromTag: %w", err)
 		}
+		if schema, ok := customSchema(tField.typ); ok {
+			if min := schemaFromTag.Value.Min; min != nil {
+				schema.Min = min
+			}
+			schemaFromTag.Value = schema
+		}
+		if tField.getTagExample() == "" {
+			if example, ok := customExample(tField.typ); ok {
+				exampleTag = example
+			}
+		}
 
 		params = append(params, &openapi3.Parameter{
 			Name:        tField.getTagName(),
@@ -120,7 +200,11 @@ func createParamsWithReflection(structPtr interface{}) ([]*openapi3.Parameter, e
 			Example:     exampleTag,
 			In:          inParam,
 			Required:    required,
+			Deprecated:  deprecated,
 			Schema:      schemaFromTag,
+			ExtensionProps: openapi3.ExtensionProps{
+				Extensions: extensions,
+			},
 		})
 	}
 	return params, nil
@@ -144,19 +228,11 @@ func schemaFromTag(schemaMinTagValue string, schemaFieldType string) (*openapi3.
 }
 
 func tagLookup(fieldName, rawTag string) (string, bool) {
-	fieldName = fieldName + ":"
-	if !strings.Contains(rawTag, fieldName) {
-		return "", false
-	}
-	splits := strings.Split(rawTag, fieldName)
-	if len(splits) < 2 {
-		return "", false
-	}
-	valueWithTail := splits[1]
-
-	val := valueWithTail
-	if idx := strings.Index(valueWithTail, ";"); idx != -1 {
-		val = valueWithTail[:idx]
+	for _, pair := range strings.Split(rawTag, ";") {
+		kv := strings.SplitN(pair, ":", 2)
+		if len(kv) == 2 && strings.TrimSpace(kv[0]) == fieldName {
+			return strings.TrimSpace(kv[1]), true
+		}
 	}
-	return strings.TrimSpace(val), true
+	return "", false
 }
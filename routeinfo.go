@@ -0,0 +1,72 @@
+package docrouter
+
+import (
+	"reflect"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// RouteInfo describes a registered route.
+type RouteInfo struct {
+	// Path template as passed in Route.Path
+	Path string
+	// ServedPath is path template the route is served at, with Options.Versioning
+	// by path it's prefixed with the newest version of the route.
+	ServedPath  string
+	Methods     []string
+	OperationID string
+	Tags        []string
+	Summary     string
+	Description string
+	Parameters  openapi3.Parameters
+	// Versions the route is served in, nil without Options.Versioning
+	Versions []string
+
+	// Go types of Route.Parameters, Route.RequestBody and Route.ResponseBody.
+	// Nil when not set on the route.
+	ParametersType   reflect.Type
+	RequestBodyType  reflect.Type
+	ResponseBodyType reflect.Type
+}
+
+func (st *routerState) addRouteInfo(route *Route, params openapi3.Parameters, versions []string, servedPath string) {
+	st.routes = append(st.routes, RouteInfo{
+		Path:        route.Path,
+		ServedPath:  servedPath,
+		Methods:     append([]string{}, route.Methods...),
+		OperationID: uniqueOperationID(route),
+		Tags:        append([]string{}, route.Tags...),
+		Summary:     route.Summary,
+		Description: route.Description,
+		Parameters:  params,
+		Versions:    versions,
+
+		ParametersType:   typeOf(route.Parameters),
+		RequestBodyType:  typeOf(route.RequestBody),
+		ResponseBodyType: typeOf(route.ResponseBody),
+	})
+}
+
+// Routes returns all registered routes in the order they were added.
+func (srv *Router) Routes() []RouteInfo {
+	defer srv.readLock()()
+	return append([]RouteInfo{}, srv.current().routes...)
+}
+
+// Walk calls walkFn for every registered route in the order they were added.
+// Walking stops at the first error, which is returned.
+func (srv *Router) Walk(walkFn func(route RouteInfo) error) error {
+	for _, route := range srv.Routes() {
+		if err := walkFn(route); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func typeOf(v interface{}) reflect.Type {
+	if v == nil {
+		return nil
+	}
+	return reflect.TypeOf(v)
+}
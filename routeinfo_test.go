@@ -0,0 +1,74 @@
+package docrouter
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoutes(t *testing.T) {
+	router := New(DefaultOptions)
+
+	type StarParameters struct {
+		StarID int `docrouter:"name:starId; kind:path; desc:Star identifier"`
+	}
+
+	noop := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	require.NoError(t, router.AddRoute(Route{
+		Path:    "/stars",
+		Methods: []string{http.MethodGet},
+		Summary: "List stars",
+		Tags:    []string{"stars"},
+		Handler: noop,
+	}))
+	require.NoError(t, router.AddRoute(Route{
+		Path:        "/stars/{starId}",
+		Methods:     []string{http.MethodGet, http.MethodPut},
+		Parameters:  &StarParameters{},
+		Summary:     "Star detail",
+		Description: "Reads or replaces a star.",
+		Tags:        []string{"stars", "detail"},
+		Handler:     noop,
+	}))
+
+	routes := router.Routes()
+	require.Len(t, routes, 2)
+
+	assert.Equal(t, "/stars", routes[0].Path)
+	assert.Equal(t, []string{http.MethodGet}, routes[0].Methods)
+	assert.Equal(t, "list-stars", routes[0].OperationID)
+	assert.Equal(t, []string{"stars"}, routes[0].Tags)
+	assert.Empty(t, routes[0].Parameters)
+
+	assert.Equal(t, "/stars/{starId}", routes[1].Path)
+	assert.Equal(t, []string{http.MethodGet, http.MethodPut}, routes[1].Methods)
+	assert.Equal(t, "star-detail", routes[1].OperationID)
+	assert.Equal(t, "Star detail", routes[1].Summary)
+	assert.Equal(t, "Reads or replaces a star.", routes[1].Description)
+	require.NotNil(t, routes[1].Parameters.GetByInAndName(openapi3.ParameterInPath, "starId"))
+
+	t.Run("walk", func(t *testing.T) {
+		visited := []string{}
+		err := router.Walk(func(route RouteInfo) error {
+			visited = append(visited, route.OperationID)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"list-stars", "star-detail"}, visited)
+	})
+
+	t.Run("walk stops on error", func(t *testing.T) {
+		errStop := errors.New("stop")
+		visited := 0
+		err := router.Walk(func(route RouteInfo) error {
+			visited++
+			return errStop
+		})
+		assert.Equal(t, errStop, err)
+		assert.Equal(t, 1, visited)
+	})
+}
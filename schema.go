@@ -0,0 +1,275 @@
+package docrouter
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaGenerator reflects Go types of request and response bodies to schemas.
+//
+// Named struct types used by several routes, recursive types and types
+// registered with Router.AddSchema are moved to document components
+// and referenced with $ref.
+type schemaGenerator struct {
+	components *openapi3.Components
+	types      map[reflect.Type]*reflectedType
+	// names maps component names to their types, so same-named types
+	// from different packages get distinct names
+	names map[string]reflect.Type
+	// route is index of the route being reflected
+	route int
+}
+
+type reflectedType struct {
+	schema *openapi3.Schema
+	// refs are all the references to the schema handed out so far,
+	// they are turned into $ref once the type becomes a component
+	refs       []*openapi3.SchemaRef
+	routes     map[int]bool
+	name       string
+	inProgress bool
+}
+
+func newSchemaGenerator(components *openapi3.Components) *schemaGenerator {
+	return &schemaGenerator{
+		components: components,
+		types:      map[reflect.Type]*reflectedType{},
+		names:      map[string]reflect.Type{},
+	}
+}
+
+// forRoute sets index of the route whose types are reflected.
+func (g *schemaGenerator) forRoute(route int) *schemaGenerator {
+	g.route = route
+	return g
+}
+
+// register reflects the type and makes it a component with given name.
+func (g *schemaGenerator) register(t reflect.Type, name string) error {
+	t = indirectType(t)
+	if owner, taken := g.names[name]; taken && owner != t {
+		return fmt.Errorf("schema name %q is already used by %s", name, owner)
+	}
+	if _, found := g.components.Schemas[name]; found && g.names[name] == nil {
+		return fmt.Errorf("schema name %q is already used", name)
+	}
+	g.schemaRef(t)
+	rt := g.types[t]
+	if rt == nil {
+		return fmt.Errorf("type %s can't be a schema component", t)
+	}
+	if rt.name != "" && rt.name != name {
+		return fmt.Errorf("type %s is already registered as %q", t, rt.name)
+	}
+	g.promote(t, rt, name)
+	return nil
+}
+
+func (g *schemaGenerator) schemaRef(t reflect.Type) *openapi3.SchemaRef {
+	t = indirectType(t)
+	if !isComponentCandidate(t) {
+		return openapi3.NewSchemaRef("", g.schema(t))
+	}
+
+	rt, found := g.types[t]
+	if !found {
+		// schema is allocated upfront, so recursive references can point to it
+		rt = &reflectedType{schema: &openapi3.Schema{}, routes: map[int]bool{}, inProgress: true}
+		g.types[t] = rt
+		*rt.schema = *g.schema(t)
+		rt.inProgress = false
+	} else if rt.inProgress && rt.name == "" {
+		// recursive type must be a component
+		g.promote(t, rt, "")
+	}
+	rt.routes[g.route] = true
+
+	ref := openapi3.NewSchemaRef("", rt.schema)
+	rt.refs = append(rt.refs, ref)
+	if rt.name == "" && len(rt.routes) > 1 {
+		g.promote(t, rt, "")
+	}
+	if rt.name != "" {
+		ref.Ref = componentSchemaRef(rt.name)
+	}
+	return ref
+}
+
+// promote moves the type schema to components and turns all its references to $ref.
+func (g *schemaGenerator) promote(t reflect.Type, rt *reflectedType, name string) {
+	if rt.name == "" {
+		if name == "" {
+			name = g.componentName(t)
+		}
+		rt.name = name
+		g.names[name] = t
+		if g.components.Schemas == nil {
+			g.components.Schemas = openapi3.Schemas{}
+		}
+		g.components.Schemas[name] = openapi3.NewSchemaRef("", rt.schema)
+	}
+	for _, ref := range rt.refs {
+		ref.Ref = componentSchemaRef(rt.name)
+	}
+}
+
+// componentName returns stable name for the type. Types with the same name from
+// different packages are prefixed with the package name, e.g. Star and V2Star.
+func (g *schemaGenerator) componentName(t reflect.Type) string {
+	base := componentIdent(t.Name())
+	candidates := []string{base, componentIdent(strings.Title(path.Base(t.PkgPath()))) + base}
+	for _, name := range candidates {
+		if !g.nameTaken(name, t) {
+			return name
+		}
+	}
+	for i := 2; ; i++ {
+		name := fmt.Sprintf("%s%d", base, i)
+		if !g.nameTaken(name, t) {
+			return name
+		}
+	}
+}
+
+func (g *schemaGenerator) nameTaken(name string, t reflect.Type) bool {
+	if owner, taken := g.names[name]; taken {
+		return owner != t
+	}
+	_, taken := g.components.Schemas[name]
+	return taken
+}
+
+func (g *schemaGenerator) schema(t reflect.Type) *openapi3.Schema {
+	schema := g.reflectSchema(t)
+	if example, ok := customExample(t); ok && schema.Example == nil {
+		schema.Example = example
+	}
+	return schema
+}
+
+func (g *schemaGenerator) reflectSchema(t reflect.Type) *openapi3.Schema {
+	if schema, ok := customSchema(t); ok {
+		return schema
+	}
+	if t == timeType {
+		return openapi3.NewDateTimeSchema()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return openapi3.NewBoolSchema()
+	case reflect.Int, reflect.Int64:
+		return openapi3.NewInt64Schema()
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return openapi3.NewInt32Schema()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return openapi3.NewIntegerSchema().WithMin(0)
+	case reflect.Float32:
+		return openapi3.NewFloat64Schema().WithFormat("float")
+	case reflect.Float64:
+		return openapi3.NewFloat64Schema().WithFormat("double")
+	case reflect.String:
+		return openapi3.NewStringSchema()
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return openapi3.NewBytesSchema()
+		}
+		schema := openapi3.NewArraySchema()
+		schema.Items = g.schemaRef(t.Elem())
+		return schema
+	case reflect.Map:
+		schema := openapi3.NewObjectSchema()
+		schema.AdditionalProperties = g.schemaRef(t.Elem())
+		return schema
+	case reflect.Struct:
+		return g.structSchema(t)
+	case reflect.Interface:
+		if o := lookupOneOf(t); o != nil {
+			return g.oneOfSchema(o)
+		}
+		return &openapi3.Schema{}
+	default:
+		// anything we can't describe accepts any value
+		return &openapi3.Schema{}
+	}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) *openapi3.Schema {
+	schema := openapi3.NewObjectSchema()
+	g.addStructFields(schema, t)
+	return schema
+}
+
+func (g *schemaGenerator) addStructFields(schema *openapi3.Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omitEmpty, skip := jsonFieldName(field)
+		if skip {
+			continue
+		}
+		if field.Anonymous && name == "" && indirectType(field.Type).Kind() == reflect.Struct {
+			// embedded struct fields are promoted by encoding/json
+			g.addStructFields(schema, indirectType(field.Type))
+			continue
+		}
+		if field.PkgPath != "" {
+			// unexported
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = g.schemaRef(field.Type)
+		if !omitEmpty && field.Type.Kind() != reflect.Ptr {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+func jsonFieldName(field reflect.StructField) (name string, omitEmpty bool, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	parts := strings.Split(tag, ",")
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return parts[0], omitEmpty, false
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// isComponentCandidate reports whether the type can be moved to components.
+// Types with custom schemas stay inline, as they usually stand for scalars.
+func isComponentCandidate(t reflect.Type) bool {
+	return t.Name() != "" && t.PkgPath() != "" && t.Kind() == reflect.Struct && t != timeType && !isSchemaer(t)
+}
+
+func componentSchemaRef(name string) string {
+	return "#/components/schemas/" + name
+}
+
+// componentIdent strips characters not allowed in component names.
+func componentIdent(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '_' || r == '-' {
+			return r
+		}
+		return -1
+	}, s)
+}
@@ -0,0 +1,74 @@
+package docrouter
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type schemaTestStar struct {
+	Name      string             `json:"name"`
+	Mass      float64            `json:"mass,omitempty"`
+	Planets   []schemaTestPlanet `json:"planets"`
+	Tags      map[string]string  `json:"tags,omitempty"`
+	Born      time.Time          `json:"born"`
+	Companion *schemaTestStar    `json:"companion"`
+	Ignored   string             `json:"-"`
+	internal  string
+	schemaTestEmbedded
+}
+
+type schemaTestPlanet struct {
+	Name string `json:"name"`
+}
+
+type schemaTestEmbedded struct {
+	Catalog string `json:"catalog"`
+}
+
+func TestSchemaGenerator(t *testing.T) {
+	components := openapi3.NewComponents()
+	g := newSchemaGenerator(&components)
+
+	ref := g.forRoute(0).schemaRef(reflect.TypeOf(&schemaTestStar{}))
+	assert.Equal(t, "#/components/schemas/schemaTestStar", ref.Ref, "recursive type is a component")
+
+	star := components.Schemas["schemaTestStar"].Value
+	require.NotNil(t, star)
+	assert.Equal(t, "object", star.Type)
+	assert.ElementsMatch(t, []string{"name", "planets", "born", "catalog"}, star.Required)
+	assert.Equal(t, "double", star.Properties["mass"].Value.Format)
+	assert.Equal(t, "date-time", star.Properties["born"].Value.Format)
+	assert.Equal(t, "#/components/schemas/schemaTestStar", star.Properties["companion"].Ref)
+	assert.Equal(t, "string", star.Properties["tags"].Value.AdditionalProperties.Value.Type)
+	assert.Contains(t, star.Properties, "catalog")
+	assert.NotContains(t, star.Properties, "Ignored")
+	assert.NotContains(t, star.Properties, "internal")
+
+	planets := star.Properties["planets"].Value
+	assert.Equal(t, "array", planets.Type)
+	assert.Empty(t, planets.Items.Ref, "type used by single route stays inline")
+	assert.NotContains(t, components.Schemas, "schemaTestPlanet")
+
+	g.forRoute(1).schemaRef(reflect.TypeOf([]schemaTestPlanet{}))
+	assert.Contains(t, components.Schemas, "schemaTestPlanet")
+	assert.Equal(t, "#/components/schemas/schemaTestPlanet", planets.Items.Ref, "earlier references become $ref")
+
+	t.Run("same name from different packages", func(t *testing.T) {
+		// Cookie has the same name as http.Cookie
+		type Cookie struct {
+			Name string `json:"name"`
+		}
+		g.forRoute(0).schemaRef(reflect.TypeOf(Cookie{}))
+		g.forRoute(1).schemaRef(reflect.TypeOf(Cookie{}))
+		g.forRoute(0).schemaRef(reflect.TypeOf(http.Cookie{}))
+		g.forRoute(1).schemaRef(reflect.TypeOf(http.Cookie{}))
+		assert.Contains(t, components.Schemas, "Cookie")
+		assert.Contains(t, components.Schemas, "HttpCookie")
+	})
+}
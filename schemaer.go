@@ -0,0 +1,74 @@
+package docrouter
+
+import (
+	"encoding"
+	"reflect"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Schemaer is implemented by types describing their own schema, which replaces
+// the reflected one. It's called on a zero value, like json.Marshaler
+// the method may have a value or a pointer receiver.
+//
+// Parameter fields implementing Schemaer should also implement
+// encoding.TextUnmarshaler, so DecodeParams can set them.
+type Schemaer interface {
+	OpenAPISchema() *openapi3.Schema
+}
+
+// Exampler is implemented by types providing an example value for their
+// schemas. Examples from docrouter tags take precedence.
+type Exampler interface {
+	OpenAPIExample() interface{}
+}
+
+var (
+	schemaerType        = reflect.TypeOf((*Schemaer)(nil)).Elem()
+	examplerType        = reflect.TypeOf((*Exampler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// zeroValueOf returns zero value of t implementing iface, either as value or as pointer.
+func zeroValueOf(t reflect.Type, iface reflect.Type) (interface{}, bool) {
+	t = indirectType(t)
+	switch {
+	case t.Kind() == reflect.Interface:
+		return nil, false
+	case t.Implements(iface):
+		return reflect.Zero(t).Interface(), true
+	case reflect.PtrTo(t).Implements(iface):
+		return reflect.New(t).Interface(), true
+	}
+	return nil, false
+}
+
+func isSchemaer(t reflect.Type) bool {
+	_, ok := zeroValueOf(t, schemaerType)
+	return ok
+}
+
+// customSchema returns schema of a type implementing Schemaer.
+func customSchema(t reflect.Type) (*openapi3.Schema, bool) {
+	v, ok := zeroValueOf(t, schemaerType)
+	if !ok {
+		return nil, false
+	}
+	schema := v.(Schemaer).OpenAPISchema()
+	if schema == nil {
+		return &openapi3.Schema{}, true
+	}
+	// callers modify the schema, so it's copied in case the method returns a shared one
+	s := *schema
+	return &s, true
+}
+
+// customExample returns example of a type implementing Exampler.
+func customExample(t reflect.Type) (interface{}, bool) {
+	v, ok := zeroValueOf(t, examplerType)
+	if !ok {
+		return nil, false
+	}
+	return v.(Exampler).OpenAPIExample(), true
+}
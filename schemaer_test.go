@@ -0,0 +1,111 @@
+package docrouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// schemaerTestDecimal stands for types like decimal.Decimal,
+// which are structs encoded as strings.
+type schemaerTestDecimal struct {
+	cents int64
+}
+
+func (d schemaerTestDecimal) OpenAPISchema() *openapi3.Schema {
+	return openapi3.NewStringSchema().WithPattern(`^-?\d+\.\d{2}$`)
+}
+
+func (d schemaerTestDecimal) OpenAPIExample() interface{} {
+	return "12.50"
+}
+
+func (d schemaerTestDecimal) MarshalText() ([]byte, error) {
+	return []byte(strconv.FormatFloat(float64(d.cents)/100, 'f', 2, 64)), nil
+}
+
+func (d *schemaerTestDecimal) UnmarshalText(text []byte) error {
+	f, err := strconv.ParseFloat(string(text), 64)
+	if err != nil {
+		return err
+	}
+	d.cents = int64(f * 100)
+	return nil
+}
+
+type schemaerTestColor string
+
+func (c *schemaerTestColor) OpenAPIExample() interface{} {
+	return "purple"
+}
+
+type schemaerTestOrder struct {
+	Price schemaerTestDecimal `json:"price"`
+	Color schemaerTestColor   `json:"color"`
+}
+
+func TestSchemaer(t *testing.T) {
+	components := openapi3.NewComponents()
+	g := newSchemaGenerator(&components)
+
+	g.forRoute(0).schemaRef(reflect.TypeOf(schemaerTestOrder{}))
+	order := g.forRoute(1).schemaRef(reflect.TypeOf(schemaerTestOrder{}))
+	require.Equal(t, "#/components/schemas/schemaerTestOrder", order.Ref)
+
+	price := order.Value.Properties["price"]
+	assert.Empty(t, price.Ref, "custom schemas stay inline")
+	assert.Equal(t, "string", price.Value.Type)
+	assert.Equal(t, `^-?\d+\.\d{2}$`, price.Value.Pattern)
+	assert.Equal(t, "12.50", price.Value.Example)
+	assert.NotContains(t, components.Schemas, "schemaerTestDecimal")
+
+	color := order.Value.Properties["color"].Value
+	assert.Equal(t, "string", color.Type)
+	assert.Equal(t, "purple", color.Example, "pointer receiver")
+}
+
+func TestSchemaerParams(t *testing.T) {
+	type params struct {
+		Price schemaerTestDecimal `docrouter:"name: price; kind: query; desc: Max price"`
+		Min   schemaerTestDecimal `docrouter:"name: min; kind: query; example: 1.00"`
+	}
+
+	router := New(DefaultOptions)
+	var decoded params
+	err := router.AddRoute(Route{
+		Path:        "/orders",
+		Methods:     []string{http.MethodGet},
+		Parameters:  &params{},
+		Summary:     "List orders",
+		OperationID: "listOrders",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := DecodeParams(&decoded, r); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			}
+		}),
+	})
+	require.NoError(t, err)
+
+	op := router.OpenAPI().Paths["/orders"].Get
+	price := op.Parameters.GetByInAndName("query", "price")
+	require.NotNil(t, price)
+	assert.Equal(t, `^-?\d+\.\d{2}$`, price.Schema.Value.Pattern)
+	assert.Equal(t, "12.50", price.Example)
+	assert.Equal(t, "1.00", op.Parameters.GetByInAndName("query", "min").Example, "tag example wins")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders?price=3.25", nil))
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, int64(325), decoded.Price.cents)
+	assert.Equal(t, int64(0), decoded.Min.cents, "missing value is left zero")
+
+	u, err := router.URL("listOrders", &params{Price: schemaerTestDecimal{cents: 450}})
+	require.NoError(t, err)
+	assert.Equal(t, "/orders?price=4.50", u.String())
+}
@@ -0,0 +1,151 @@
+package docrouter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gorilla/mux"
+)
+
+// ServerMatching controls whether the documented servers affect request matching.
+type ServerMatching int
+
+const (
+	// ServerMatchNone uses servers for documentation only.
+	ServerMatchNone ServerMatching = iota
+	// ServerMatchPath matches routes under base paths of the servers only,
+	// e.g. GET /v3/stars for server https://www.example.com/v3 and route /stars.
+	ServerMatchPath
+	// ServerMatchHostAndPath matches request host against the servers too.
+	ServerMatchHostAndPath
+)
+
+// ServerVariableDoc is a variable substituted in server URL template,
+// e.g. region in https://{region}.example.com.
+type ServerVariableDoc struct {
+	Default     string
+	Enum        []string
+	Description string
+}
+
+func (s ServerDoc) openAPI3() *openapi3.Server {
+	server := &openapi3.Server{
+		URL:         s.URL,
+		Description: s.Description,
+	}
+	for name, v := range s.Variables {
+		if server.Variables == nil {
+			server.Variables = map[string]*openapi3.ServerVariable{}
+		}
+		server.Variables[name] = &openapi3.ServerVariable{
+			Default:     v.Default,
+			Enum:        v.Enum,
+			Description: v.Description,
+		}
+	}
+	return server
+}
+
+// serverVariableRE matches {name} in server URL templates.
+var serverVariableRE = regexp.MustCompile(`{([^{}]*)}`)
+
+// splitServerURL splits server URL template to host and path.
+func splitServerURL(u string) (host, path string) {
+	i := strings.Index(u, "://")
+	if i == -1 {
+		return "", u
+	}
+	u = u[i+len("://"):]
+	if j := strings.Index(u, "/"); j != -1 {
+		return u[:j], u[j:]
+	}
+	return u, ""
+}
+
+// serverMuxTemplates translates server URL to gorilla/mux host and path prefix templates.
+// Variables with enum match their values only.
+func serverMuxTemplates(server *openapi3.Server) (host, pathPrefix string, err error) {
+	host, pathPrefix = splitServerURL(server.URL)
+
+	translate := func(template string) (string, error) {
+		var tErr error
+		out := serverVariableRE.ReplaceAllStringFunc(template, func(match string) string {
+			name := match[1 : len(match)-1]
+			v, found := server.Variables[name]
+			if !found {
+				tErr = fmt.Errorf("server %q: variable %q is not defined", server.URL, name)
+				return match
+			}
+			if len(v.Enum) == 0 {
+				return match
+			}
+			values := make([]string, 0, len(v.Enum))
+			for _, value := range v.Enum {
+				values = append(values, regexp.QuoteMeta(value))
+			}
+			sort.Strings(values)
+			return "{" + name + ":(?:" + strings.Join(values, "|") + ")}"
+		})
+		return out, tErr
+	}
+	if host, err = translate(host); err != nil {
+		return "", "", err
+	}
+	if pathPrefix, err = translate(strings.TrimRight(pathPrefix, "/")); err != nil {
+		return "", "", err
+	}
+	return host, pathPrefix, nil
+}
+
+// serverBasePath returns path prefix routes of the state are served under,
+// it's base path of the first server with Options.ServerMatching.
+// Server variables take their default values.
+func (srv *Router) serverBasePath(st *routerState) string {
+	if srv.opts.ServerMatching == ServerMatchNone || len(st.docRoot.Servers) == 0 {
+		return ""
+	}
+	server := st.docRoot.Servers[0]
+	_, pathPrefix := splitServerURL(server.URL)
+	return serverVariableRE.ReplaceAllStringFunc(strings.TrimRight(pathPrefix, "/"), func(match string) string {
+		if v, found := server.Variables[match[1:len(match)-1]]; found {
+			return v.Default
+		}
+		return match
+	})
+}
+
+// handlerRouters returns routers handlers of the state are registered on. With
+// Options.ServerMatching, it's a subrouter for each server, otherwise the mux router.
+func (srv *Router) handlerRouters(st *routerState) ([]*mux.Router, error) {
+	if st.handlerRouters != nil {
+		return st.handlerRouters, nil
+	}
+	if srv.opts.ServerMatching == ServerMatchNone || len(st.docRoot.Servers) == 0 {
+		st.handlerRouters = []*mux.Router{st.muxRouter}
+		return st.handlerRouters, nil
+	}
+
+	routers := []*mux.Router{}
+	for _, server := range st.docRoot.Servers {
+		host, pathPrefix, err := serverMuxTemplates(server)
+		if err != nil {
+			return nil, err
+		}
+		r := st.muxRouter.NewRoute()
+		if host != "" && srv.opts.ServerMatching == ServerMatchHostAndPath {
+			r = r.Host(host)
+		}
+		if pathPrefix != "" {
+			r = r.PathPrefix(pathPrefix)
+		}
+		if err := r.GetError(); err != nil {
+			return nil, fmt.Errorf("server %q: %v", server.URL, err)
+		}
+		routers = append(routers, r.Subrouter())
+	}
+	st.handlerRouters = routers
+	return routers, nil
+}
@@ -0,0 +1,100 @@
+package docrouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerMatching(t *testing.T) {
+	servers := []ServerDoc{
+		{
+			URL:         "https://{region}.example.com/{version}",
+			Description: "Production",
+			Variables: map[string]ServerVariableDoc{
+				"region":  {Default: "eu", Enum: []string{"eu", "us"}},
+				"version": {Default: "v3", Enum: []string{"v3", "v4"}, Description: "API version"},
+			},
+		},
+		{URL: "/api/"},
+	}
+	newRouter := func(matching ServerMatching) *Router {
+		opts := DefaultOptions
+		opts.Servers = servers
+		opts.ServerMatching = matching
+		opts.AutoOptions = true
+		router := New(opts)
+		require.NoError(t, router.AddRoute(Route{
+			Path:    "/stars",
+			Methods: []string{http.MethodGet},
+			Summary: "List stars",
+			Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		}))
+		return router
+	}
+	status := func(router *Router, method, url string) int {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(method, url, nil))
+		return rec.Code
+	}
+
+	t.Run("documented variables", func(t *testing.T) {
+		doc := newRouter(ServerMatchNone).OpenAPI()
+		require.Len(t, doc.Servers, 2)
+		version := doc.Servers[0].Variables["version"]
+		assert.Equal(t, "v3", version.Default)
+		assert.Equal(t, []string{"v3", "v4"}, version.Enum)
+		assert.Equal(t, "API version", version.Description)
+	})
+
+	t.Run("none", func(t *testing.T) {
+		router := newRouter(ServerMatchNone)
+		assert.Equal(t, http.StatusOK, status(router, http.MethodGet, "http://localhost/stars"))
+		assert.Equal(t, http.StatusNotFound, status(router, http.MethodGet, "http://localhost/v3/stars"))
+	})
+
+	t.Run("path", func(t *testing.T) {
+		router := newRouter(ServerMatchPath)
+		assert.Equal(t, http.StatusOK, status(router, http.MethodGet, "http://localhost/v3/stars"))
+		assert.Equal(t, http.StatusOK, status(router, http.MethodGet, "http://localhost/v4/stars"))
+		assert.Equal(t, http.StatusOK, status(router, http.MethodGet, "http://localhost/api/stars"))
+		assert.Equal(t, http.StatusNoContent, status(router, http.MethodOptions, "http://localhost/v3/stars"))
+		assert.Equal(t, http.StatusNotFound, status(router, http.MethodGet, "http://localhost/v5/stars"))
+		assert.Equal(t, http.StatusNotFound, status(router, http.MethodGet, "http://localhost/stars"))
+	})
+
+	t.Run("url", func(t *testing.T) {
+		for _, matching := range []ServerMatching{ServerMatchNone, ServerMatchPath} {
+			router := newRouter(matching)
+			u, err := router.URL("list-stars", nil)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, status(router, http.MethodGet, "http://localhost"+u.String()), u.String())
+		}
+		u, err := newRouter(ServerMatchPath).URL("list-stars", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "/v3/stars", u.String(), "base path of the first server with default variables")
+	})
+
+	t.Run("host and path", func(t *testing.T) {
+		router := newRouter(ServerMatchHostAndPath)
+		assert.Equal(t, http.StatusOK, status(router, http.MethodGet, "http://us.example.com/v3/stars"))
+		assert.Equal(t, http.StatusNotFound, status(router, http.MethodGet, "http://asia.example.com/v3/stars"))
+		assert.Equal(t, http.StatusOK, status(router, http.MethodGet, "http://localhost/api/stars"), "relative server matches any host")
+	})
+
+	t.Run("undefined variable", func(t *testing.T) {
+		opts := DefaultOptions
+		opts.Servers = []ServerDoc{{URL: "https://{region}.example.com"}}
+		opts.ServerMatching = ServerMatchHostAndPath
+		err := New(opts).AddRoute(Route{
+			Path:    "/stars",
+			Methods: []string{http.MethodGet},
+			Summary: "List stars",
+			Handler: http.NotFoundHandler(),
+		})
+		assert.EqualError(t, err, `register handler: server "https://{region}.example.com": variable "region" is not defined`)
+	})
+}
@@ -0,0 +1,63 @@
+package docrouter
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/ghodss/yaml"
+)
+
+// OpenAPI returns OpenAPI document of the registered routes.
+// It's always OpenAPI 3.0, Options.SpecVersion only affects the encoded documents.
+// The document is owned by the router and must not be modified. It's a snapshot,
+// routes added later are documented in a new document, so it can be read
+// concurrently with AddRoute.
+func (srv *Router) OpenAPI() *openapi3.T {
+	defer srv.readLock()()
+	st := srv.current()
+	if !srv.isFrozen() {
+		atomic.StoreInt32(&st.published, 1)
+	}
+	return st.docRoot
+}
+
+// OpenAPIJSON returns OpenAPI document of the registered routes encoded as JSON
+// in version set by Options.SpecVersion.
+func (srv *Router) OpenAPIJSON() ([]byte, error) {
+	defer srv.readLock()()
+	st := srv.current()
+	if st.specJSON != nil {
+		return append([]byte{}, st.specJSON...), nil
+	}
+	return st.marshalDoc(srv.opts.SpecVersion)
+}
+
+func (st *routerState) marshalDoc(version string) ([]byte, error) {
+	b, err := json.Marshal(st.docRoot)
+	if err != nil {
+		return nil, fmt.Errorf("marshal json: %v", err)
+	}
+	switch version {
+	case "", OpenAPI30:
+		return b, nil
+	case OpenAPI31:
+		return convertTo31(b)
+	default:
+		return nil, fmt.Errorf("unsupported OpenAPI version %q", version)
+	}
+}
+
+// OpenAPIYAML returns OpenAPI document of the registered routes encoded as YAML.
+func (srv *Router) OpenAPIYAML() ([]byte, error) {
+	b, err := srv.OpenAPIJSON()
+	if err != nil {
+		return nil, err
+	}
+	y, err := yaml.JSONToYAML(b)
+	if err != nil {
+		return nil, fmt.Errorf("convert json to yaml: %v", err)
+	}
+	return y, nil
+}
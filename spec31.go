@@ -0,0 +1,181 @@
+package docrouter
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// OpenAPI versions of the documents produced by the router.
+const (
+	OpenAPI30 = "3.0.0"
+	OpenAPI31 = "3.1.0"
+)
+
+// webhooksExtension holds webhooks in OpenAPI 3.0 documents,
+// they are moved to the webhooks field in OpenAPI 3.1.
+const webhooksExtension = "x-webhooks"
+
+// convertTo31 converts JSON encoded OpenAPI 3.0 document to OpenAPI 3.1.
+// Schemas are translated to JSON Schema 2020-12.
+func convertTo31(doc30 []byte) ([]byte, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(doc30, &doc); err != nil {
+		return nil, fmt.Errorf("unmarshal json: %v", err)
+	}
+	doc["openapi"] = OpenAPI31
+	if webhooks, found := doc[webhooksExtension]; found {
+		delete(doc, webhooksExtension)
+		doc["webhooks"] = webhooks
+	}
+
+	for _, key := range []string{"paths", "webhooks"} {
+		for _, item := range objectValues(doc[key]) {
+			convertPathItem31(item)
+		}
+	}
+	if components, ok := doc["components"].(map[string]interface{}); ok {
+		for _, schema := range objectValues(components["schemas"]) {
+			convertSchema31(schema)
+		}
+		for _, param := range objectValues(components["parameters"]) {
+			convertParameter31(param)
+		}
+		for _, header := range objectValues(components["headers"]) {
+			convertParameter31(header)
+		}
+		for _, response := range objectValues(components["responses"]) {
+			convertResponse31(response)
+		}
+		for _, body := range objectValues(components["requestBodies"]) {
+			convertContent31(body["content"])
+		}
+	}
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("marshal json: %v", err)
+	}
+	return b, nil
+}
+
+func convertPathItem31(item map[string]interface{}) {
+	for _, param := range objectList(item["parameters"]) {
+		convertParameter31(param)
+	}
+	for _, method := range []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"} {
+		op, ok := item[method].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, param := range objectList(op["parameters"]) {
+			convertParameter31(param)
+		}
+		if body, ok := op["requestBody"].(map[string]interface{}); ok {
+			convertContent31(body["content"])
+		}
+		for _, response := range objectValues(op["responses"]) {
+			convertResponse31(response)
+		}
+	}
+}
+
+// convertParameter31 converts parameter or header.
+func convertParameter31(param map[string]interface{}) {
+	if schema, ok := param["schema"].(map[string]interface{}); ok {
+		convertSchema31(schema)
+	}
+	convertContent31(param["content"])
+}
+
+func convertResponse31(response map[string]interface{}) {
+	for _, header := range objectValues(response["headers"]) {
+		convertParameter31(header)
+	}
+	convertContent31(response["content"])
+}
+
+func convertContent31(content interface{}) {
+	for _, mediaType := range objectValues(content) {
+		if schema, ok := mediaType["schema"].(map[string]interface{}); ok {
+			convertSchema31(schema)
+		}
+	}
+}
+
+func convertSchema31(schema map[string]interface{}) {
+	if nullable, _ := schema["nullable"].(bool); nullable {
+		delete(schema, "nullable")
+		if t, ok := schema["type"].(string); ok {
+			schema["type"] = []interface{}{t, "null"}
+			if enum, ok := schema["enum"].([]interface{}); ok {
+				schema["enum"] = append(enum, nil)
+			}
+		} else {
+			// schemas without single type, e.g. compositions, allow null in anyOf
+			inner := map[string]interface{}{}
+			for key, value := range schema {
+				inner[key] = value
+				delete(schema, key)
+			}
+			schema["anyOf"] = []interface{}{inner, map[string]interface{}{"type": "null"}}
+		}
+	}
+	delete(schema, "nullable")
+
+	if example, found := schema["example"]; found {
+		delete(schema, "example")
+		schema["examples"] = []interface{}{example}
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) == 1 {
+		delete(schema, "enum")
+		schema["const"] = enum[0]
+	}
+	for _, bound := range []string{"Minimum", "Maximum"} {
+		key := "exclusive" + bound
+		limit := "m" + bound[1:]
+		if exclusive, _ := schema[key].(bool); exclusive && schema[limit] != nil {
+			schema[key] = schema[limit]
+			delete(schema, limit)
+		} else {
+			delete(schema, key)
+		}
+	}
+
+	for _, key := range []string{"items", "additionalProperties", "not"} {
+		if sub, ok := schema[key].(map[string]interface{}); ok {
+			convertSchema31(sub)
+		}
+	}
+	for _, sub := range objectValues(schema["properties"]) {
+		convertSchema31(sub)
+	}
+	for _, key := range []string{"oneOf", "anyOf", "allOf"} {
+		for _, sub := range objectList(schema[key]) {
+			convertSchema31(sub)
+		}
+	}
+}
+
+// objectValues returns values of JSON object, which are objects.
+func objectValues(v interface{}) []map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	values := make([]map[string]interface{}, 0, len(m))
+	for _, value := range m {
+		if obj, ok := value.(map[string]interface{}); ok {
+			values = append(values, obj)
+		}
+	}
+	return values
+}
+
+// objectList returns items of JSON array, which are objects.
+func objectList(v interface{}) []map[string]interface{} {
+	list, _ := v.([]interface{})
+	items := make([]map[string]interface{}, 0, len(list))
+	for _, item := range list {
+		if obj, ok := item.(map[string]interface{}); ok {
+			items = append(items, obj)
+		}
+	}
+	return items
+}
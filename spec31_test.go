@@ -0,0 +1,114 @@
+package docrouter
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type spec31TestEvent struct {
+	Kind    string  `json:"kind"`
+	Comment *string `json:"comment"`
+}
+
+func TestSpecVersion31(t *testing.T) {
+	opts := DefaultOptions
+	opts.SpecVersion = OpenAPI31
+	router := New(opts)
+
+	type params struct {
+		Limit int `docrouter:"name: limit; kind: query; example: 10; schemaMin: 1"`
+	}
+	require.NoError(t, router.AddRoute(Route{
+		Path:         "/events",
+		Methods:      []string{http.MethodGet},
+		Parameters:   &params{},
+		ResponseBody: []spec31TestEvent{},
+		Summary:      "List events",
+		Handler:      http.NotFoundHandler(),
+	}))
+	require.NoError(t, router.AddWebhook("newEvent", Route{
+		RequestBody: spec31TestEvent{},
+		Summary:     "New event",
+	}))
+	router.OpenAPI().Paths["/events"].Get.Responses["200"].Value.Content["application/json"].Schema.Value.Items.Value.Properties["comment"].Value.Nullable = true
+	schema := router.OpenAPI().Components.Schemas
+	require.Contains(t, schema, "spec31TestEvent", "used by route and webhook")
+	schema["spec31TestEvent"].Value.Properties["kind"].Value.Enum = []interface{}{"created"}
+	schema["spec31TestEvent"].Value.Properties["kind"].Value.Example = "created"
+	router.OpenAPI().Paths["/events"].Get.Parameters[0].Value.Schema.Value.ExclusiveMin = true
+
+	b, err := router.OpenAPIJSON()
+	require.NoError(t, err)
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &doc))
+
+	assert.Equal(t, "3.1.0", doc["openapi"])
+	assert.NotContains(t, doc, "x-webhooks")
+	webhook := doc["webhooks"].(map[string]interface{})["newEvent"].(map[string]interface{})
+	assert.Contains(t, webhook, "post")
+
+	event := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})["spec31TestEvent"].(map[string]interface{})
+	kind := event["properties"].(map[string]interface{})["kind"].(map[string]interface{})
+	assert.Equal(t, "created", kind["const"])
+	assert.NotContains(t, kind, "enum")
+	assert.Equal(t, []interface{}{"created"}, kind["examples"])
+	assert.NotContains(t, kind, "example")
+	comment := event["properties"].(map[string]interface{})["comment"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"string", "null"}, comment["type"])
+	assert.NotContains(t, comment, "nullable")
+
+	param := doc["paths"].(map[string]interface{})["/events"].(map[string]interface{})["get"].(map[string]interface{})["parameters"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, 10.0, param["example"], "parameter examples are kept")
+	limit := param["schema"].(map[string]interface{})
+	assert.Equal(t, 1.0, limit["exclusiveMinimum"])
+	assert.NotContains(t, limit, "minimum")
+
+	t.Run("3.0 keeps webhooks in extension", func(t *testing.T) {
+		router := New(DefaultOptions)
+		require.NoError(t, router.AddWebhook("newEvent", Route{RequestBody: spec31TestEvent{}, Summary: "New event"}))
+		assert.Error(t, router.AddWebhook("newEvent", Route{Summary: "New event"}), "duplicate")
+
+		var doc openapi3.T
+		b, err := router.OpenAPIJSON()
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(b, &doc))
+		assert.Equal(t, "3.0.0", doc.OpenAPI)
+		assert.Contains(t, doc.Extensions, "x-webhooks")
+	})
+}
+
+func TestConvertSchema31Nullable(t *testing.T) {
+	t.Run("enum", func(t *testing.T) {
+		schema := map[string]interface{}{"type": "string", "enum": []interface{}{"a"}, "nullable": true}
+		convertSchema31(schema)
+		assert.Equal(t, map[string]interface{}{
+			"type": []interface{}{"string", "null"},
+			"enum": []interface{}{"a", nil},
+		}, schema)
+	})
+
+	t.Run("composition", func(t *testing.T) {
+		schema := map[string]interface{}{
+			"oneOf": []interface{}{
+				map[string]interface{}{"$ref": "#/components/schemas/circle"},
+				map[string]interface{}{"$ref": "#/components/schemas/square"},
+			},
+			"nullable": true,
+		}
+		convertSchema31(schema)
+		assert.Equal(t, map[string]interface{}{
+			"anyOf": []interface{}{
+				map[string]interface{}{"oneOf": []interface{}{
+					map[string]interface{}{"$ref": "#/components/schemas/circle"},
+					map[string]interface{}{"$ref": "#/components/schemas/square"},
+				}},
+				map[string]interface{}{"type": "null"},
+			},
+		}, schema)
+	})
+}
@@ -0,0 +1,389 @@
+// Package specdiff compares two OpenAPI documents and classifies changes
+// as breaking or non-breaking for existing API clients.
+package specdiff
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type Severity string
+
+const (
+	Breaking    Severity = "breaking"
+	NonBreaking Severity = "non-breaking"
+)
+
+// Change is a single difference between two documents.
+type Change struct {
+	Severity Severity
+	// Location in the document, e.g. "GET /stars/{starId}"
+	Location string
+	Message  string
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s: %s", c.Severity, c.Location, c.Message)
+}
+
+// HasBreaking reports whether any of the changes is breaking.
+func HasBreaking(changes []Change) bool {
+	for _, c := range changes {
+		if c.Severity == Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// Compare returns changes made in revision compared to base.
+// Changes are ordered by location.
+//
+// Operations are matched by method and path with path variables ignored,
+// so renaming a path variable isn't a breaking change. Parameters declared
+// on path items apply to all their operations. Request and response bodies
+// are compared by media type.
+func Compare(base, revision *openapi3.T) []Change {
+	d := differ{base: base, revision: revision}
+	baseOps, revisionOps := operations(base), operations(revision)
+	for _, key := range sortedKeys(baseOps, revisionOps) {
+		baseOp, inBase := baseOps[key]
+		revisionOp, inRevision := revisionOps[key]
+		switch {
+		case !inRevision:
+			d.add(Breaking, baseOp.location, "operation removed")
+		case !inBase:
+			d.add(NonBreaking, revisionOp.location, "operation added")
+		default:
+			d.compareOperations(revisionOp.location, baseOp, revisionOp)
+		}
+	}
+	return d.changes
+}
+
+type differ struct {
+	base, revision *openapi3.T
+	changes        []Change
+	// compared holds pairs of schemas being compared, so recursive schemas terminate
+	compared map[[2]*openapi3.Schema]bool
+}
+
+// operation is an operation with parameters of its path item.
+type operation struct {
+	*openapi3.Operation
+	location string
+	params   map[string]*openapi3.Parameter
+}
+
+func (d *differ) add(severity Severity, location, format string, args ...interface{}) {
+	d.changes = append(d.changes, Change{
+		Severity: severity,
+		Location: location,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (d *differ) compareOperations(location string, base, revision operation) {
+	if base.OperationID != revision.OperationID {
+		d.add(NonBreaking, location, "operationId changed from %q to %q", base.OperationID, revision.OperationID)
+	}
+	if !base.Deprecated && revision.Deprecated {
+		d.add(NonBreaking, location, "operation deprecated")
+	}
+
+	baseParams, revisionParams := base.params, revision.params
+	for _, key := range sortedKeys(baseParams, revisionParams) {
+		baseParam, inBase := baseParams[key]
+		revisionParam, inRevision := revisionParams[key]
+		paramLocation := fmt.Sprintf("%s %s parameter %q", location, revisionOrBase(revisionParam, baseParam).In, revisionOrBase(revisionParam, baseParam).Name)
+		switch {
+		case !inRevision:
+			d.add(NonBreaking, paramLocation, "parameter removed")
+		case !inBase && revisionParam.Required:
+			d.add(Breaking, paramLocation, "required parameter added")
+		case !inBase:
+			d.add(NonBreaking, paramLocation, "optional parameter added")
+		default:
+			if baseParam.Name != revisionParam.Name {
+				d.add(NonBreaking, paramLocation, "path variable renamed from %q", baseParam.Name)
+			}
+			if !baseParam.Required && revisionParam.Required {
+				d.add(Breaking, paramLocation, "parameter became required")
+			}
+			if baseParam.Required && !revisionParam.Required {
+				d.add(NonBreaking, paramLocation, "parameter became optional")
+			}
+			d.compareSchemas(paramLocation, baseParam.Schema, revisionParam.Schema, request)
+		}
+	}
+
+	baseBody, revisionBody := requestBody(base.Operation), requestBody(revision.Operation)
+	baseBodyRequired := baseBody != nil && baseBody.Required
+	revisionBodyRequired := revisionBody != nil && revisionBody.Required
+	if !baseBodyRequired && revisionBodyRequired {
+		d.add(Breaking, location, "request body became required")
+	}
+	if baseBody != nil && revisionBody != nil {
+		d.compareContent(location+" request body", baseBody.Content, revisionBody.Content, request)
+	}
+
+	for _, status := range sortedKeys(base.Responses, revision.Responses) {
+		baseResponse, revisionResponse := base.Responses[status], revision.Responses[status]
+		if baseResponse == nil || baseResponse.Value == nil || revisionResponse == nil || revisionResponse.Value == nil {
+			continue
+		}
+		d.compareContent(location+" response "+status, baseResponse.Value.Content, revisionResponse.Value.Content, response)
+	}
+}
+
+// direction says whether schema describes data sent by clients or to them.
+type direction int
+
+const (
+	request direction = iota
+	response
+)
+
+func (d *differ) compareContent(location string, base, revision openapi3.Content, dir direction) {
+	for _, mediaType := range sortedKeys(base, revision) {
+		baseMedia, revisionMedia := base[mediaType], revision[mediaType]
+		if baseMedia == nil || revisionMedia == nil {
+			continue
+		}
+		d.compareSchemas(location+" "+mediaType, baseMedia.Schema, revisionMedia.Schema, dir)
+	}
+}
+
+// compareSchemas reports changes breaking clients. Narrowing of accepted values
+// breaks clients sending values valid against the base schema, removing
+// properties or widening values breaks clients reading responses.
+func (d *differ) compareSchemas(location string, baseRef, revisionRef *openapi3.SchemaRef, dir direction) {
+	base, revision := resolveSchema(d.base, baseRef), resolveSchema(d.revision, revisionRef)
+	if base == nil || revision == nil {
+		return
+	}
+	pair := [2]*openapi3.Schema{base, revision}
+	if d.compared[pair] {
+		return
+	}
+	if d.compared == nil {
+		d.compared = map[[2]*openapi3.Schema]bool{}
+	}
+	d.compared[pair] = true
+	defer delete(d.compared, pair)
+
+	if base.Type != revision.Type {
+		severity := Breaking
+		if dir == request && base.Type == "integer" && revision.Type == "number" {
+			severity = NonBreaking
+		}
+		d.add(severity, location, "type changed from %q to %q", base.Type, revision.Type)
+	}
+	if dir == response {
+		d.compareResponseSchemas(location, base, revision)
+	} else {
+		d.compareRequestSchemas(location, base, revision)
+	}
+	if base.Items != nil && revision.Items != nil {
+		d.compareSchemas(location+"[]", base.Items, revision.Items, dir)
+	}
+	for _, name := range sortedKeys(base.Properties, revision.Properties) {
+		baseProp, revisionProp := base.Properties[name], revision.Properties[name]
+		if baseProp != nil && revisionProp != nil {
+			d.compareSchemas(location+"."+name, baseProp, revisionProp, dir)
+		}
+	}
+}
+
+func (d *differ) compareResponseSchemas(location string, base, revision *openapi3.Schema) {
+	for _, name := range sortedKeys(base.Properties, revision.Properties) {
+		_, inRevision := revision.Properties[name]
+		switch {
+		case base.Properties[name] == nil:
+			d.add(NonBreaking, location, "property %q added", name)
+		case !inRevision:
+			d.add(Breaking, location, "property %q removed", name)
+		case containsString(base.Required, name) && !containsString(revision.Required, name):
+			d.add(Breaking, location, "property %q became optional", name)
+		}
+	}
+	if len(base.Enum) > 0 {
+		for _, v := range revision.Enum {
+			if !containsValue(base.Enum, v) {
+				d.add(Breaking, location, "enum value %v added", v)
+			}
+		}
+	}
+}
+
+func (d *differ) compareRequestSchemas(location string, base, revision *openapi3.Schema) {
+	for _, name := range sortedKeys(base.Properties, revision.Properties) {
+		_, inBase := base.Properties[name]
+		required := containsString(revision.Required, name)
+		switch {
+		case revision.Properties[name] == nil:
+			d.add(NonBreaking, location, "property %q removed", name)
+		case !inBase && required:
+			d.add(Breaking, location, "required property %q added", name)
+		case !inBase:
+			d.add(NonBreaking, location, "optional property %q added", name)
+		case required && !containsString(base.Required, name):
+			d.add(Breaking, location, "property %q became required", name)
+		}
+	}
+	if base.Format != revision.Format && revision.Format != "" {
+		d.add(Breaking, location, "format changed from %q to %q", base.Format, revision.Format)
+	}
+	if isNarrowerMin(base.Min, revision.Min) {
+		d.add(Breaking, location, "minimum increased to %v", *revision.Min)
+	}
+	if isNarrowerMax(base.Max, revision.Max) {
+		d.add(Breaking, location, "maximum decreased to %v", *revision.Max)
+	}
+	if revision.MinLength > base.MinLength {
+		d.add(Breaking, location, "minLength increased to %d", revision.MinLength)
+	}
+	if revision.MaxLength != nil && (base.MaxLength == nil || *revision.MaxLength < *base.MaxLength) {
+		d.add(Breaking, location, "maxLength decreased to %d", *revision.MaxLength)
+	}
+	if revision.Pattern != "" && revision.Pattern != base.Pattern {
+		d.add(Breaking, location, "pattern changed to %q", revision.Pattern)
+	}
+	if len(revision.Enum) > 0 {
+		for _, v := range base.Enum {
+			if !containsValue(revision.Enum, v) {
+				d.add(Breaking, location, "enum value %v removed", v)
+			}
+		}
+		if len(base.Enum) == 0 {
+			d.add(Breaking, location, "enum restriction added")
+		}
+	}
+}
+
+func isNarrowerMin(base, revision *float64) bool {
+	return revision != nil && (base == nil || *revision > *base)
+}
+
+func isNarrowerMax(base, revision *float64) bool {
+	return revision != nil && (base == nil || *revision < *base)
+}
+
+func containsValue(values []interface{}, v interface{}) bool {
+	for _, x := range values {
+		if fmt.Sprint(x) == fmt.Sprint(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// pathVariableRE matches {name} in path templates.
+var pathVariableRE = regexp.MustCompile(`{[^{}]*}`)
+
+// operations returns operations of the document by method and path with
+// path variables replaced by their position, e.g. "GET /stars/{0}".
+func operations(doc *openapi3.T) map[string]operation {
+	ops := map[string]operation{}
+	for path, pathItem := range doc.Paths {
+		variables := map[string]int{}
+		for i, v := range pathVariableRE.FindAllString(path, -1) {
+			variables[v[1:len(v)-1]] = i
+		}
+		i := 0
+		normalized := pathVariableRE.ReplaceAllStringFunc(path, func(string) string {
+			i++
+			return fmt.Sprintf("{%d}", i-1)
+		})
+		for method, op := range pathItem.Operations() {
+			params := map[string]*openapi3.Parameter{}
+			addParameters(params, pathItem.Parameters, variables)
+			// operation parameters override parameters of the path item
+			addParameters(params, op.Parameters, variables)
+			location := strings.ToUpper(method) + " " + path
+			ops[strings.ToUpper(method)+" "+normalized] = operation{Operation: op, location: location, params: params}
+		}
+	}
+	return ops
+}
+
+// addParameters adds parameters by their location and name,
+// path parameters are identified by position of their variable.
+func addParameters(params map[string]*openapi3.Parameter, refs openapi3.Parameters, variables map[string]int) {
+	for _, ref := range refs {
+		if ref.Value == nil {
+			continue
+		}
+		name := ref.Value.Name
+		switch ref.Value.In {
+		case openapi3.ParameterInHeader:
+			// header names are case insensitive
+			name = strings.ToLower(name)
+		case openapi3.ParameterInPath:
+			if i, found := variables[name]; found {
+				name = fmt.Sprintf("{%d}", i)
+			}
+		}
+		params[ref.Value.In+":"+name] = ref.Value
+	}
+}
+
+func requestBody(op *openapi3.Operation) *openapi3.RequestBody {
+	if op.RequestBody == nil {
+		return nil
+	}
+	return op.RequestBody.Value
+}
+
+// resolveSchema returns schema of the reference, references to schema
+// components not resolved by a loader are looked up in the document.
+func resolveSchema(doc *openapi3.T, ref *openapi3.SchemaRef) *openapi3.Schema {
+	for seen := 0; ref != nil && seen < 32; seen++ {
+		if ref.Value != nil {
+			return ref.Value
+		}
+		name := strings.TrimPrefix(ref.Ref, "#/components/schemas/")
+		if name == ref.Ref {
+			return nil
+		}
+		ref = doc.Components.Schemas[name]
+	}
+	return nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func revisionOrBase(revision, base *openapi3.Parameter) *openapi3.Parameter {
+	if revision != nil {
+		return revision
+	}
+	return base
+}
+
+func sortedKeys(maps ...interface{}) []string {
+	set := map[string]bool{}
+	for _, m := range maps {
+		v := reflect.ValueOf(m)
+		for _, k := range v.MapKeys() {
+			set[k.String()] = true
+		}
+	}
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
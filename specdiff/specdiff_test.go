@@ -0,0 +1,133 @@
+package specdiff
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	newDoc := func(ops map[string]*openapi3.Operation) *openapi3.T {
+		doc := &openapi3.T{OpenAPI: "3.0.0", Paths: openapi3.Paths{}}
+		for path, op := range ops {
+			doc.AddOperation(path, "GET", op)
+		}
+		return doc
+	}
+	param := func(in, name string, required bool, schema *openapi3.Schema) *openapi3.ParameterRef {
+		return &openapi3.ParameterRef{Value: &openapi3.Parameter{
+			In:       in,
+			Name:     name,
+			Required: required,
+			Schema:   schema.NewRef(),
+		}}
+	}
+
+	base := newDoc(map[string]*openapi3.Operation{
+		"/stars": {
+			OperationID: "listStars",
+			Parameters: openapi3.Parameters{
+				param("query", "limit", false, openapi3.NewIntegerSchema()),
+				param("query", "color", false, openapi3.NewStringSchema().WithEnum("red", "blue")),
+				param("header", "X-Trace", false, openapi3.NewStringSchema()),
+			},
+		},
+		"/planets": {OperationID: "listPlanets"},
+	})
+	revision := newDoc(map[string]*openapi3.Operation{
+		"/stars": {
+			OperationID: "listStars",
+			Parameters: openapi3.Parameters{
+				param("query", "limit", true, openapi3.NewIntegerSchema().WithMin(1)),
+				param("query", "color", false, openapi3.NewStringSchema().WithEnum("red")),
+				param("query", "galaxy", true, openapi3.NewStringSchema()),
+				param("query", "verbose", false, openapi3.NewBoolSchema()),
+			},
+		},
+		"/moons": {OperationID: "listMoons"},
+	})
+
+	changes := Compare(base, revision)
+	assert.Equal(t, []Change{
+		{NonBreaking, "GET /moons", "operation added"},
+		{Breaking, "GET /planets", "operation removed"},
+		{NonBreaking, `GET /stars header parameter "X-Trace"`, "parameter removed"},
+		{Breaking, `GET /stars query parameter "color"`, "enum value blue removed"},
+		{Breaking, `GET /stars query parameter "galaxy"`, "required parameter added"},
+		{Breaking, `GET /stars query parameter "limit"`, "parameter became required"},
+		{Breaking, `GET /stars query parameter "limit"`, "minimum increased to 1"},
+		{NonBreaking, `GET /stars query parameter "verbose"`, "optional parameter added"},
+	}, changes)
+	assert.True(t, HasBreaking(changes))
+
+	assert.Empty(t, Compare(base, base))
+}
+
+func TestComparePathVariableRenamed(t *testing.T) {
+	newDoc := func(path, variable string) *openapi3.T {
+		doc := &openapi3.T{OpenAPI: "3.0.0", Paths: openapi3.Paths{}}
+		doc.AddOperation(path, "GET", &openapi3.Operation{
+			OperationID: "getStar",
+			Parameters: openapi3.Parameters{{Value: openapi3.NewPathParameter(variable).
+				WithSchema(openapi3.NewStringSchema())}},
+		})
+		return doc
+	}
+
+	changes := Compare(newDoc("/stars/{id}", "id"), newDoc("/stars/{starID}", "starID"))
+	assert.Equal(t, []Change{
+		{NonBreaking, `GET /stars/{starID} path parameter "starID"`, `path variable renamed from "id"`},
+	}, changes)
+	assert.False(t, HasBreaking(changes))
+}
+
+func TestComparePathItemParameters(t *testing.T) {
+	newDoc := func(required bool) *openapi3.T {
+		doc := &openapi3.T{OpenAPI: "3.0.0", Paths: openapi3.Paths{}}
+		doc.AddOperation("/stars", "GET", &openapi3.Operation{OperationID: "listStars"})
+		doc.Paths["/stars"].Parameters = openapi3.Parameters{{Value: openapi3.NewQueryParameter("galaxy").
+			WithRequired(required).
+			WithSchema(openapi3.NewStringSchema())}}
+		return doc
+	}
+
+	assert.Equal(t, []Change{
+		{Breaking, `GET /stars query parameter "galaxy"`, "parameter became required"},
+	}, Compare(newDoc(false), newDoc(true)))
+}
+
+func TestCompareBodies(t *testing.T) {
+	newDoc := func(star *openapi3.Schema) *openapi3.T {
+		doc := &openapi3.T{OpenAPI: "3.0.0", Paths: openapi3.Paths{}}
+		doc.Components.Schemas = openapi3.Schemas{"Star": star.NewRef()}
+		ref := &openapi3.SchemaRef{Ref: "#/components/schemas/Star"}
+		doc.AddOperation("/stars", "POST", &openapi3.Operation{
+			OperationID: "createStar",
+			RequestBody: &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithJSONSchemaRef(ref)},
+			Responses: openapi3.Responses{
+				"200": {Value: openapi3.NewResponse().WithJSONSchemaRef(ref)},
+			},
+		})
+		return doc
+	}
+	base := openapi3.NewObjectSchema().
+		WithProperty("name", openapi3.NewStringSchema()).
+		WithProperty("mass", openapi3.NewIntegerSchema()).
+		WithProperty("color", openapi3.NewStringSchema().WithEnum("red", "blue"))
+	base.Required = []string{"name"}
+	revision := openapi3.NewObjectSchema().
+		WithProperty("name", openapi3.NewStringSchema().WithMaxLength(10)).
+		WithProperty("color", openapi3.NewStringSchema().WithEnum("red", "blue", "green")).
+		WithProperty("galaxy", openapi3.NewStringSchema())
+	revision.Required = []string{"name", "galaxy"}
+
+	assert.Equal(t, []Change{
+		{Breaking, "POST /stars request body application/json", `required property "galaxy" added`},
+		{NonBreaking, "POST /stars request body application/json", `property "mass" removed`},
+		{Breaking, "POST /stars request body application/json.name", "maxLength decreased to 10"},
+		{NonBreaking, "POST /stars response 200 application/json", `property "galaxy" added`},
+		{Breaking, "POST /stars response 200 application/json", `property "mass" removed`},
+		{Breaking, "POST /stars response 200 application/json.color", "enum value green added"},
+	}, Compare(newDoc(base), newDoc(revision)))
+}
@@ -0,0 +1,209 @@
+package docrouter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gorilla/mux"
+)
+
+// specBinding links operation of a spec with handler attached by Bind.
+type specBinding struct {
+	route   *routers.Route
+	handler http.Handler
+}
+
+// NewFromSpec creates router serving operations of an existing OpenAPI document.
+//
+// Routes are registered from the document paths and handlers are attached
+// to them by operation ID with Bind. Requests are validated against the
+// document before they reach the handler, so handlers can rely on DecodeParams.
+// Title, Version and Servers options are ignored, the document is used as is,
+// so Options.ServerMatching matches requests against servers of the document.
+// Bindings are checked before requests are served, requests fail with 500
+// status until every operation is bound. Freeze fails then too.
+func NewFromSpec(doc *openapi3.T, opts Options) (*Router, error) {
+	if opts.Versioning != nil {
+		return nil, fmt.Errorf("versioning isn't supported for routers created from spec")
+	}
+	if opts.CORS != nil {
+		if err := opts.CORS.validate(); err != nil {
+			return nil, err
+		}
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid spec: %v", err)
+	}
+
+	srv := &Router{
+		opts:         opts,
+		specBindings: map[string]*specBinding{},
+		bindingCheck: &serveCheck{},
+	}
+	st := newRouterState(doc)
+	srv.state.Store(st)
+	for _, path := range sortedPaths(doc.Paths) {
+		pathItem := doc.Paths[path]
+		for _, method := range sortedMethods(pathItem) {
+			operation := pathItem.GetOperation(method)
+			if operation.OperationID == "" {
+				return nil, fmt.Errorf("operation %s %s has no operationId", method, path)
+			}
+			if _, found := srv.specBindings[operation.OperationID]; found {
+				return nil, fmt.Errorf("duplicate operationId %q", operation.OperationID)
+			}
+
+			binding := &specBinding{
+				route: &routers.Route{
+					Spec:      doc,
+					Path:      path,
+					PathItem:  pathItem,
+					Method:    method,
+					Operation: operation,
+				},
+			}
+			srv.specBindings[operation.OperationID] = binding
+
+			route := Route{
+				Path:        path,
+				Methods:     []string{method},
+				Summary:     operation.Summary,
+				Description: operation.Description,
+				OperationID: operation.OperationID,
+				Tags:        operation.Tags,
+				Deprecated:  operation.Deprecated,
+				Handler:     srv.bindingHandler(binding),
+			}
+			if err := srv.registerHandler(st, &route, nil); err != nil {
+				return nil, fmt.Errorf("register handler: %v", err)
+			}
+			params := append(openapi3.Parameters{}, pathItem.Parameters...)
+			st.routes = append(st.routes, RouteInfo{
+				Path:        path,
+				ServedPath:  path,
+				Methods:     route.Methods,
+				OperationID: operation.OperationID,
+				Tags:        append([]string{}, operation.Tags...),
+				Summary:     operation.Summary,
+				Description: operation.Description,
+				Parameters:  append(params, operation.Parameters...),
+			})
+		}
+	}
+	return srv, nil
+}
+
+// Bind attaches handler to operation of the router created with NewFromSpec.
+//
+// Binding unknown operation or binding an operation twice returns error,
+// which is also reported by CheckBindings.
+func (srv *Router) Bind(operationID string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+	srv.writeMu.Lock()
+	defer srv.writeMu.Unlock()
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.isFrozen() {
+		return ErrFrozen
+	}
+	err := srv.bind(operationID, handler, middlewares)
+	srv.bindingCheck = &serveCheck{}
+	if err != nil {
+		srv.bindErrs = append(srv.bindErrs, err)
+	}
+	return err
+}
+
+func (srv *Router) bind(operationID string, handler http.Handler, middlewares []func(http.Handler) http.Handler) error {
+	if srv.specBindings == nil {
+		return fmt.Errorf("bind %q: router wasn't created from spec", operationID)
+	}
+	if handler == nil {
+		return fmt.Errorf("bind %q: handler is nil", operationID)
+	}
+	binding, found := srv.specBindings[operationID]
+	if !found {
+		return fmt.Errorf("bind %q: operation not found in spec", operationID)
+	}
+	if binding.handler != nil {
+		return fmt.Errorf("bind %q: operation is already bound", operationID)
+	}
+	binding.handler = handlerWithMiddlewares(validateRequest(binding.route, handler), middlewares)
+	return nil
+}
+
+// CheckBindings reports operations of a router created with NewFromSpec
+// which have no handler bound, and failed Bind calls.
+// It should be called before serving, so a mismatch between the spec
+// and the handlers fails startup.
+func (srv *Router) CheckBindings() error {
+	defer srv.readLock()()
+	return srv.checkBindings()
+}
+
+// checkBindingsOnServe runs CheckBindings before the first request
+// is served and again after the bindings change.
+func (srv *Router) checkBindingsOnServe(w http.ResponseWriter) bool {
+	return srv.bindingCheck.serve(w, srv.checkBindings)
+}
+
+func (srv *Router) checkBindings() error {
+	if srv.specBindings == nil {
+		return nil
+	}
+	problems := []string{}
+	for _, err := range srv.bindErrs {
+		problems = append(problems, err.Error())
+	}
+	unbound := []string{}
+	for operationID, binding := range srv.specBindings {
+		if binding.handler == nil {
+			unbound = append(unbound, operationID)
+		}
+	}
+	sort.Strings(unbound)
+	for _, operationID := range unbound {
+		problems = append(problems, fmt.Sprintf("operation %q is not bound", operationID))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("spec bindings: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func (srv *Router) bindingHandler(b *specBinding) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		unlock := srv.readLock()
+		handler := b.handler
+		unlock()
+		if handler == nil {
+			http.Error(w, "operation not implemented", http.StatusNotImplemented)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+func validateRequest(route *routers.Route, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := openapi3filter.ValidateRequest(r.Context(), &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: mux.Vars(r),
+			Route:      route,
+			Options: &openapi3filter.Options{
+				AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+			},
+		})
+		if err != nil {
+			reportDecodeError(r.Context(), err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
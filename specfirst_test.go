@@ -0,0 +1,151 @@
+package docrouter
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const starsSpec = `
+openapi: 3.0.0
+info:
+  title: Stars
+  version: "1.0"
+paths:
+  /stars/{starId}:
+    get:
+      operationId: getStar
+      parameters:
+        - name: starId
+          in: path
+          required: true
+          schema:
+            type: integer
+            minimum: 1
+      responses:
+        "200":
+          description: Star
+  /stars:
+    post:
+      operationId: createStar
+      responses:
+        "201":
+          description: Created star
+`
+
+func TestNewFromSpec(t *testing.T) {
+	loadSpec := func(t *testing.T) *openapi3.T {
+		doc, err := openapi3.NewLoader().LoadFromData([]byte(starsSpec))
+		require.NoError(t, err)
+		return doc
+	}
+
+	t.Run("bound operations", func(t *testing.T) {
+		router, err := NewFromSpec(loadSpec(t), DefaultOptions)
+		require.NoError(t, err)
+
+		type StarParameters struct {
+			StarID int `docrouter:"name:starId; kind:path"`
+		}
+		err = router.Bind("getStar", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var params StarParameters
+			require.NoError(t, DecodeParams(&params, r))
+			fmt.Fprintf(w, "star %d", params.StarID)
+		}))
+		require.NoError(t, err)
+		require.Error(t, router.CheckBindings())
+
+		require.NoError(t, router.Bind("createStar", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+		})))
+		require.NoError(t, router.CheckBindings())
+
+		ts := httptest.NewServer(router)
+		defer ts.Close()
+
+		resp, err := http.Get(ts.URL + "/stars/5")
+		require.NoError(t, err)
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "star 5", string(respBytes))
+
+		resp, err = http.Get(ts.URL + "/stars/0")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "minimum is validated")
+
+		resp, err = http.Post(ts.URL+"/stars", "application/json", nil)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		routes := router.Routes()
+		require.Len(t, routes, 2)
+		assert.Equal(t, "createStar", routes[0].OperationID)
+		assert.Equal(t, "getStar", routes[1].OperationID)
+		require.NotNil(t, routes[1].Parameters.GetByInAndName(openapi3.ParameterInPath, "starId"))
+	})
+
+	t.Run("unbound operation", func(t *testing.T) {
+		router, err := NewFromSpec(loadSpec(t), DefaultOptions)
+		require.NoError(t, err)
+		require.NoError(t, router.Bind("createStar", http.NotFoundHandler()))
+
+		err = router.CheckBindings()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `operation "getStar" is not bound`)
+
+		ts := httptest.NewServer(router)
+		defer ts.Close()
+		resp, err := http.Get(ts.URL + "/stars/5")
+		require.NoError(t, err)
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		assert.NotContains(t, string(respBytes), "getStar", "details are logged only")
+		assert.Error(t, router.Freeze())
+
+		require.NoError(t, router.Bind("getStar", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+		resp, err = http.Get(ts.URL + "/stars/5")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "bindings are checked again")
+		assert.NoError(t, router.Freeze())
+	})
+
+	t.Run("unknown operation", func(t *testing.T) {
+		router, err := NewFromSpec(loadSpec(t), DefaultOptions)
+		require.NoError(t, err)
+		require.NoError(t, router.Bind("getStar", http.NotFoundHandler()))
+		require.NoError(t, router.Bind("createStar", http.NotFoundHandler()))
+		assert.Error(t, router.Bind("deleteStar", http.NotFoundHandler()))
+		assert.Error(t, router.Bind("getStar", http.NotFoundHandler()))
+
+		err = router.CheckBindings()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `bind "deleteStar": operation not found in spec`)
+	})
+
+	t.Run("add route is rejected", func(t *testing.T) {
+		router, err := NewFromSpec(loadSpec(t), DefaultOptions)
+		require.NoError(t, err)
+		err = router.AddRoute(Route{
+			Path:    "/planets",
+			Methods: []string{http.MethodGet},
+			Summary: "List planets",
+			Handler: http.NotFoundHandler(),
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("bind on code-first router", func(t *testing.T) {
+		assert.Error(t, New(DefaultOptions).Bind("getStar", http.NotFoundHandler()))
+	})
+}
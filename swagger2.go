@@ -0,0 +1,288 @@
+package docrouter
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ConversionLoss is a feature of OpenAPI 3.0 document which can't be expressed
+// in the converted document and was dropped.
+type ConversionLoss struct {
+	// Location in the document, e.g. "GET /stars/{starId}"
+	Location string
+	Message  string
+}
+
+func (l ConversionLoss) String() string {
+	return fmt.Sprintf("%s: %s", l.Location, l.Message)
+}
+
+// SwaggerV2JSON returns Swagger 2.0 document of the registered routes encoded as JSON.
+// Features without Swagger 2.0 counterpart, e.g. oneOf schemas or cookie parameters,
+// are dropped and reported as losses. Parameter examples and nullable schemas are
+// kept in x-example and x-nullable extensions.
+func (srv *Router) SwaggerV2JSON() ([]byte, []ConversionLoss, error) {
+	b, err := srv.openAPI30JSON()
+	if err != nil {
+		return nil, nil, err
+	}
+	// the conversion modifies the document, so it works on a copy
+	var doc openapi3.T
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, nil, fmt.Errorf("unmarshal json: %v", err)
+	}
+
+	c := &v2Converter{losses: []ConversionLoss{}}
+	c.prepare(&doc)
+	bodies := takeRequestBodies(&doc)
+	doc2, err := openapi2conv.FromV3(&doc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("convert to swagger 2.0: %v", err)
+	}
+	for _, body := range bodies {
+		op := doc2.Paths[body.path].GetOperation(body.method)
+		param, err := openapi2conv.FromV3RequestBody("body", body.ref, body.ref.Value.Content.Get(jsonMediaType), &doc.Components)
+		if err != nil {
+			return nil, nil, fmt.Errorf("convert request body of %s %s: %v", body.method, body.path, err)
+		}
+		op.Parameters = append(op.Parameters, param)
+		op.Consumes = []string{jsonMediaType}
+	}
+	b, err = json.Marshal(doc2)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal json: %v", err)
+	}
+	return b, c.losses, nil
+}
+
+const jsonMediaType = "application/json"
+
+type v2RequestBody struct {
+	path, method string
+	ref          *openapi3.RequestBodyRef
+}
+
+// takeRequestBodies removes JSON request bodies from operations. They are converted
+// separately, as openapi2conv turns inline object schemas into formData parameters.
+func takeRequestBodies(doc *openapi3.T) []v2RequestBody {
+	bodies := []v2RequestBody{}
+	for path, pathItem := range doc.Paths {
+		for method, op := range pathItem.Operations() {
+			body := op.RequestBody
+			if body == nil || body.Ref != "" || body.Value == nil || body.Value.Content.Get(jsonMediaType) == nil {
+				continue
+			}
+			bodies = append(bodies, v2RequestBody{path: path, method: method, ref: body})
+			op.RequestBody = nil
+		}
+	}
+	return bodies
+}
+
+// openAPI30JSON returns OpenAPI 3.0 document encoded as JSON regardless of Options.SpecVersion.
+func (srv *Router) openAPI30JSON() ([]byte, error) {
+	defer srv.readLock()()
+	return srv.current().marshalDoc(OpenAPI30)
+}
+
+// v2Converter removes features unsupported by Swagger 2.0 from OpenAPI 3.0 document
+// and records them as losses.
+type v2Converter struct {
+	losses []ConversionLoss
+	// cookieParams are references to removed cookie parameter components
+	cookieParams map[string]bool
+}
+
+func (c *v2Converter) lost(location, format string, args ...interface{}) {
+	c.losses = append(c.losses, ConversionLoss{Location: location, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *v2Converter) prepare(doc *openapi3.T) {
+	for i, server := range doc.Servers {
+		if i > 0 {
+			c.lost("servers", "server %q, only the first server is used as host and base path", server.URL)
+		}
+		if len(server.Variables) > 0 {
+			c.lost("servers", "variables of server %q", server.URL)
+		}
+	}
+	if len(doc.Servers) > 1 {
+		doc.Servers = doc.Servers[:1]
+	}
+
+	components := &doc.Components
+	c.cookieParams = map[string]bool{}
+	for _, name := range sortedKeys(components.Parameters) {
+		if param := components.Parameters[name].Value; param != nil && param.In == openapi3.ParameterInCookie {
+			c.lost("components.parameters."+name, "cookie parameter %q", param.Name)
+			delete(components.Parameters, name)
+			c.cookieParams["#/components/parameters/"+name] = true
+		}
+	}
+
+	for _, path := range sortedPaths(doc.Paths) {
+		pathItem := doc.Paths[path]
+		pathItem.Parameters = c.parameters(path, pathItem.Parameters)
+		for _, method := range sortedMethods(pathItem) {
+			c.operation(method+" "+path, pathItem.GetOperation(method))
+		}
+	}
+
+	for _, name := range sortedKeys(components.Schemas) {
+		c.schema("components.schemas."+name, components.Schemas[name])
+	}
+	for _, name := range sortedKeys(components.Responses) {
+		c.response("components.responses."+name, components.Responses[name])
+	}
+	for _, name := range sortedKeys(components.Headers) {
+		c.lost("components.headers."+name, "header component")
+	}
+	for _, name := range sortedKeys(components.Examples) {
+		c.lost("components.examples."+name, "example component")
+	}
+	for _, name := range sortedKeys(components.Links) {
+		c.lost("components.links."+name, "link component")
+	}
+	for _, name := range sortedKeys(components.Callbacks) {
+		c.lost("components.callbacks."+name, "callback component")
+	}
+	components.Headers, components.Examples, components.Links, components.Callbacks = nil, nil, nil, nil
+}
+
+func (c *v2Converter) operation(location string, op *openapi3.Operation) {
+	op.Parameters = c.parameters(location, op.Parameters)
+	if body := op.RequestBody; body != nil && body.Value != nil {
+		c.content(location+" request body", body.Value.Content)
+	}
+	for _, status := range sortedKeys(op.Responses) {
+		c.response(location+" response "+status, op.Responses[status])
+	}
+	if len(op.Callbacks) > 0 {
+		c.lost(location, "callbacks")
+		op.Callbacks = nil
+	}
+}
+
+func (c *v2Converter) parameters(location string, params openapi3.Parameters) openapi3.Parameters {
+	kept := openapi3.Parameters{}
+	for _, ref := range params {
+		param := ref.Value
+		if c.cookieParams[ref.Ref] {
+			c.lost(location, "cookie parameter %s", ref.Ref)
+			continue
+		}
+		if ref.Ref != "" || param == nil {
+			kept = append(kept, ref)
+			continue
+		}
+		if param.In == openapi3.ParameterInCookie {
+			c.lost(location, "cookie parameter %q", param.Name)
+			continue
+		}
+		if param.Example != nil {
+			if param.Extensions == nil {
+				param.Extensions = map[string]interface{}{}
+			}
+			param.Extensions["x-example"] = param.Example
+			param.Example = nil
+		}
+		if len(param.Content) > 0 {
+			c.lost(location, "content of %s parameter %q", param.In, param.Name)
+		}
+		c.schema(fmt.Sprintf("%s %s parameter %q", location, param.In, param.Name), param.Schema)
+		kept = append(kept, ref)
+	}
+	return kept
+}
+
+func (c *v2Converter) response(location string, ref *openapi3.ResponseRef) {
+	if ref.Ref != "" || ref.Value == nil {
+		return
+	}
+	response := ref.Value
+	if len(response.Headers) > 0 {
+		c.lost(location, "headers")
+		response.Headers = nil
+	}
+	if len(response.Links) > 0 {
+		c.lost(location, "links")
+		response.Links = nil
+	}
+	c.content(location, response.Content)
+}
+
+func (c *v2Converter) content(location string, content openapi3.Content) {
+	for _, mediaType := range sortedKeys(content) {
+		if mediaType != jsonMediaType {
+			c.lost(location, "media type %q", mediaType)
+			continue
+		}
+		if len(content[mediaType].Examples) > 0 || content[mediaType].Example != nil {
+			c.lost(location, "examples of media type %q", mediaType)
+		}
+		c.schema(location, content[mediaType].Schema)
+	}
+}
+
+// schema removes unsupported keywords from schema and its inline subschemas.
+func (c *v2Converter) schema(location string, ref *openapi3.SchemaRef) {
+	if ref == nil || ref.Ref != "" || ref.Value == nil {
+		return
+	}
+	schema := ref.Value
+	if len(schema.OneOf) > 0 {
+		c.lost(location, "oneOf")
+		schema.OneOf = nil
+	}
+	if len(schema.AnyOf) > 0 {
+		c.lost(location, "anyOf")
+		schema.AnyOf = nil
+	}
+	if schema.Not != nil {
+		c.lost(location, "not")
+		schema.Not = nil
+	}
+	if schema.Discriminator != nil {
+		c.lost(location, "discriminator %q", schema.Discriminator.PropertyName)
+		schema.Discriminator = nil
+	}
+	if schema.WriteOnly {
+		c.lost(location, "writeOnly")
+		schema.WriteOnly = false
+	}
+	if schema.Deprecated {
+		c.lost(location, "deprecated schema")
+		schema.Deprecated = false
+	}
+	if schema.Nullable {
+		if schema.Extensions == nil {
+			schema.Extensions = map[string]interface{}{}
+		}
+		schema.Extensions["x-nullable"] = true
+		schema.Nullable = false
+	}
+
+	c.schema(location+"[]", schema.Items)
+	c.schema(location+"{}", schema.AdditionalProperties)
+	for _, name := range sortedKeys(schema.Properties) {
+		c.schema(location+"."+name, schema.Properties[name])
+	}
+	for i, sub := range schema.AllOf {
+		c.schema(fmt.Sprintf("%s.allOf[%d]", location, i), sub)
+	}
+}
+
+// sortedKeys returns sorted keys of a map with string keys.
+func sortedKeys(m interface{}) []string {
+	keys := []string{}
+	for _, k := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
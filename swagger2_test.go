@@ -0,0 +1,112 @@
+package docrouter
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwaggerV2JSON(t *testing.T) {
+	router := New(DefaultOptions)
+
+	type params struct {
+		DrawingID int    `docrouter:"name: drawingId; kind: path; example: 7"`
+		Session   string `docrouter:"name: session; kind: cookie"`
+	}
+	require.NoError(t, router.AddRoute(Route{
+		Path:         "/drawings/{drawingId}",
+		Methods:      []string{http.MethodPut},
+		Parameters:   &params{},
+		RequestBody:  oneOfTestDrawing{},
+		ResponseBody: oneOfTestDrawing{},
+		Summary:      "Update drawing",
+		OperationID:  "updateDrawing",
+		Handler:      http.NotFoundHandler(),
+	}))
+
+	b, losses, err := router.SwaggerV2JSON()
+	require.NoError(t, err)
+
+	var doc openapi2.T
+	require.NoError(t, json.Unmarshal(b, &doc))
+	assert.Equal(t, "2.0", doc.Swagger)
+	assert.Equal(t, "www.example.com", doc.Host)
+	assert.Equal(t, "/v3", doc.BasePath)
+
+	op := doc.Paths["/drawings/{drawingId}"].Put
+	require.NotNil(t, op)
+	assert.Equal(t, "updateDrawing", op.OperationID)
+	names := []string{}
+	for _, param := range op.Parameters {
+		names = append(names, param.In+":"+param.Name)
+	}
+	assert.ElementsMatch(t, []string{"path:drawingId", "body:body"}, names)
+	for _, param := range op.Parameters {
+		switch param.In {
+		case "path":
+			assert.Equal(t, json.RawMessage("7"), param.Extensions["x-example"])
+		case "body":
+			assert.True(t, param.Required)
+			assert.Contains(t, param.Schema.Value.Properties, "shapes")
+		}
+	}
+	assert.Contains(t, op.Responses["200"].Schema.Value.Properties, "main")
+	assert.Contains(t, doc.Definitions, "oneOfTestSquare")
+	assert.NotContains(t, string(b), "oneOf\"")
+	assert.NotContains(t, string(b), "discriminator")
+
+	messages := []string{}
+	for _, loss := range losses {
+		messages = append(messages, loss.String())
+	}
+	assert.Contains(t, messages, `servers: server "https://test.example.com/v3", only the first server is used as host and base path`)
+	assert.Contains(t, messages, `PUT /drawings/{drawingId}: cookie parameter "session"`)
+	assert.Contains(t, messages, `PUT /drawings/{drawingId} request body.main: oneOf`)
+	assert.Contains(t, messages, `PUT /drawings/{drawingId} response 200.shapes[]: discriminator "kind"`)
+
+	body := router.OpenAPI().Paths["/drawings/{drawingId}"].Put.RequestBody.Value.Content["application/json"].Schema
+	assert.NotNil(t, body.Value.Properties["main"].Value.OneOf, "router's document is not modified")
+}
+
+func TestSwaggerV2JSONCookieComponent(t *testing.T) {
+	router := New(DefaultOptions)
+	require.NoError(t, router.AddParameter("Session", openapi3.NewCookieParameter("session").
+		WithSchema(openapi3.NewStringSchema())))
+
+	type params struct {
+		Session string `docrouter:"name: session; kind: cookie"`
+		Limit   int    `docrouter:"name: limit; kind: query"`
+	}
+	require.NoError(t, router.AddRoute(Route{
+		Path:        "/drawings",
+		Methods:     []string{http.MethodGet},
+		Parameters:  &params{},
+		Summary:     "List drawings",
+		OperationID: "listDrawings",
+		Handler:     http.NotFoundHandler(),
+	}))
+	require.Equal(t, "#/components/parameters/Session", router.OpenAPI().Paths["/drawings"].Get.Parameters[0].Ref)
+
+	b, losses, err := router.SwaggerV2JSON()
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "#/parameters/Session")
+
+	var doc openapi2.T
+	require.NoError(t, json.Unmarshal(b, &doc))
+	op := doc.Paths["/drawings"].Get
+	require.Len(t, op.Parameters, 1)
+	assert.Equal(t, "limit", op.Parameters[0].Name)
+	assert.Empty(t, doc.Parameters)
+
+	messages := []string{}
+	for _, loss := range losses {
+		messages = append(messages, loss.String())
+	}
+	assert.Contains(t, messages, `components.parameters.Session: cookie parameter "session"`)
+	assert.Contains(t, messages, `GET /drawings: cookie parameter #/components/parameters/Session`)
+}
@@ -0,0 +1,133 @@
+package docrouter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// timeoutExtension documents Route.Timeout in milliseconds.
+const timeoutExtension = "x-timeout"
+
+// timeoutMiddleware cancels context of requests taking longer than timeout.
+// The response is buffered, so the handler can't write anything after
+// the client is answered with 503. Panics of handlers answered with 503
+// are passed to onLatePanic.
+func timeoutMiddleware(timeout time.Duration, onLatePanic func(r *http.Request, recovered interface{}, stack []byte)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			tw := &timeoutWriter{header: http.Header{}}
+			done := make(chan struct{})
+			panicked := make(chan *handlerPanic, 1)
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						hp := &handlerPanic{value: p, stack: debug.Stack()}
+						tw.mu.Lock()
+						defer tw.mu.Unlock()
+						if !tw.timedOut {
+							panicked <- hp
+						} else if p != http.ErrAbortHandler {
+							onLatePanic(r, hp.value, hp.stack)
+						}
+						return
+					}
+					close(done)
+				}()
+				next.ServeHTTP(tw, r.WithContext(ctx))
+			}()
+
+			select {
+			case p := <-panicked:
+				p.repanic()
+			case <-done:
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				tw.writeTo(w)
+			case <-ctx.Done():
+				tw.mu.Lock()
+				tw.timedOut = true
+				tw.mu.Unlock()
+				select {
+				case p := <-panicked:
+					// the handler panicked before the timeout was noticed
+					p.repanic()
+				default:
+				}
+				writeProblem(w, http.StatusServiceUnavailable, "request timed out")
+			}
+		})
+	}
+}
+
+// handlerPanic is panic of a handler running in another goroutine,
+// raised again in the serving goroutine with stack of the panicking one.
+type handlerPanic struct {
+	value interface{}
+	stack []byte
+}
+
+func (p *handlerPanic) String() string {
+	return fmt.Sprintf("%v\n%s", p.value, p.stack)
+}
+
+// repanic panics in the serving goroutine, e.g. for Options.Recover.
+func (p *handlerPanic) repanic() {
+	if p.value == http.ErrAbortHandler {
+		panic(p.value)
+	}
+	panic(p)
+}
+
+// timeoutWriter buffers response of a handler running with timeout.
+type timeoutWriter struct {
+	mu       sync.Mutex
+	header   http.Header
+	body     bytes.Buffer
+	status   int
+	timedOut bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(status int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.status != 0 {
+		return
+	}
+	tw.status = status
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if tw.status == 0 {
+		tw.status = http.StatusOK
+	}
+	return tw.body.Write(b)
+}
+
+func (tw *timeoutWriter) writeTo(w http.ResponseWriter) {
+	dst := w.Header()
+	for k, v := range tw.header {
+		dst[k] = v
+	}
+	if tw.status == 0 {
+		tw.status = http.StatusOK
+	}
+	w.WriteHeader(tw.status)
+	_, _ = w.Write(tw.body.Bytes())
+}
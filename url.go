@@ -0,0 +1,99 @@
+package docrouter
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gorilla/mux"
+)
+
+// URL builds URL of the route identified by operationID.
+//
+// The params is a pointer to the same parameters struct as used with DecodeParams.
+// Path parameters are substituted in the path template and query parameters are
+// encoded in the query string. Header and cookie parameters are ignored.
+// Versioned routes use path of their newest version. With Options.ServerMatching,
+// the path is prefixed with base path of the first server, the host isn't included.
+func (srv *Router) URL(operationID string, params interface{}) (*url.URL, error) {
+	unlock := srv.readLock()
+	routes := srv.current().routes
+	basePath := srv.serverBasePath(srv.current())
+	unlock()
+	for _, route := range routes {
+		if hasOperationID(route.OperationID, route.Methods, operationID) {
+			return BuildURL(basePath+route.ServedPath, params)
+		}
+	}
+	return nil, fmt.Errorf("operation %q not found", operationID)
+}
+
+// BuildURL builds URL from the path template and parameters struct pointer.
+// See Router.URL for details.
+func BuildURL(pathTemplate string, params interface{}) (*url.URL, error) {
+	pathValues := []string{}
+	query := url.Values{}
+	if params != nil {
+		pParam, err := parseParameter(params)
+		if err != nil {
+			return nil, fmt.Errorf("parsing param: %w", err)
+		}
+		sElem := reflect.ValueOf(params).Elem()
+		for _, tField := range pParam.fields {
+			paramName, paramKind := tField.getTagName(), tField.getTagKind()
+			if paramName == "" {
+				continue
+			}
+			fieldVal := sElem.FieldByName(tField.name)
+			if fieldVal.Kind() == reflect.Ptr && fieldVal.IsNil() {
+				continue
+			}
+			valueStr, err := strValueFromStructField(fieldVal)
+			if err != nil {
+				return nil, fmt.Errorf("field %q: %w", tField.name, err)
+			}
+
+			switch paramKind {
+			case openapi3.ParameterInPath:
+				pathValues = append(pathValues, paramName, valueStr)
+			case openapi3.ParameterInQuery:
+				required, _ := strconv.ParseBool(tField.getTagRequired())
+				if fieldVal.IsZero() && !required {
+					continue
+				}
+				query.Set(paramName, valueStr)
+			}
+		}
+	}
+
+	muxRoute := mux.NewRouter().NewRoute().Path(pathTemplate)
+	u, err := muxRoute.URLPath(pathValues...)
+	if err != nil {
+		return nil, fmt.Errorf("build path: %v", err)
+	}
+	u.RawQuery = query.Encode()
+	return u, nil
+}
+
+func strValueFromStructField(structField reflect.Value) (string, error) {
+	if structField.Kind() == reflect.Ptr && structField.IsNil() {
+		return "", fmt.Errorf("nil value")
+	}
+	if structField.Type().Implements(textMarshalerType) {
+		text, err := structField.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+	switch structField.Kind() {
+	case reflect.Int:
+		return strconv.Itoa(int(structField.Int())), nil
+	case reflect.Bool:
+		return strconv.FormatBool(structField.Bool()), nil
+	case reflect.String:
+		return structField.String(), nil
+	default:
+		return "", fmt.Errorf("unsupported conversion for %v", structField.Kind())
+	}
+}
@@ -0,0 +1,82 @@
+package docrouter
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURL(t *testing.T) {
+	router := New(DefaultOptions)
+
+	type StarParameters struct {
+		StarID  int    `docrouter:"name:starId; kind:path"`
+		Galaxy  string `docrouter:"name:galaxy; kind:path"`
+		Limit   int    `docrouter:"name:limit; kind:query"`
+		Bright  bool   `docrouter:"name:bright; kind:query; required:true"`
+		Filter  string `docrouter:"name:filter; kind:query"`
+		Session string `docrouter:"name:session; kind:cookie"`
+	}
+
+	noop := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	require.NoError(t, router.AddRoute(Route{
+		Path:        "/galaxies/{galaxy}/stars/{starId:[0-9]+}",
+		Methods:     []string{http.MethodGet},
+		Parameters:  &StarParameters{},
+		Summary:     "Get star",
+		OperationID: "getStar",
+		Handler:     noop,
+	}))
+	require.NoError(t, router.AddRoute(Route{
+		Path:    "/stars",
+		Methods: []string{http.MethodGet},
+		Summary: "List stars",
+		Handler: noop,
+	}))
+
+	t.Run("path and query params", func(t *testing.T) {
+		u, err := router.URL("getStar", &StarParameters{
+			StarID:  5,
+			Galaxy:  "milky way",
+			Filter:  "a&b",
+			Session: "secret",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "/galaxies/milky%20way/stars/5?bright=false&filter=a%26b", u.String())
+	})
+
+	t.Run("no params", func(t *testing.T) {
+		u, err := router.URL("list-stars", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "/stars", u.String())
+	})
+
+	t.Run("path param doesn't match pattern", func(t *testing.T) {
+		_, err := BuildURL("/stars/{starId:[0-9]+}", &struct {
+			StarID string `docrouter:"name:starId; kind:path"`
+		}{StarID: "abc"})
+		assert.Error(t, err)
+	})
+
+	t.Run("nil text marshaler", func(t *testing.T) {
+		type Parameters struct {
+			Since *time.Time `docrouter:"name:since; kind:query"`
+		}
+		u, err := BuildURL("/stars", &Parameters{})
+		require.NoError(t, err)
+		assert.Equal(t, "/stars", u.String())
+
+		since := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+		u, err = BuildURL("/stars", &Parameters{Since: &since})
+		require.NoError(t, err)
+		assert.Equal(t, "/stars?since=2020-01-02T00%3A00%3A00Z", u.String())
+	})
+
+	t.Run("unknown operation", func(t *testing.T) {
+		_, err := router.URL("unknown", nil)
+		assert.Error(t, err)
+	})
+}
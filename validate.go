@@ -0,0 +1,119 @@
+package docrouter
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ValidationError is returned by Router.Validate.
+type ValidationError struct {
+	// Err is set when the document isn't valid OpenAPI document
+	Err error
+	// Issues are lint issues which failed the validation
+	Issues []LintIssue
+}
+
+func (e *ValidationError) Error() string {
+	problems := []string{}
+	if e.Err != nil {
+		problems = append(problems, e.Err.Error())
+	}
+	for _, issue := range e.Issues {
+		problems = append(problems, issue.String())
+	}
+	return "invalid OpenAPI document: " + strings.Join(problems, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Validate checks the generated document is a valid OpenAPI document
+// and that it passes Lint rules. Lint warnings fail the validation only
+// with Options.StrictValidation. Routers created with NewFromSpec
+// also check bindings with CheckBindings.
+func (srv *Router) Validate(ctx context.Context) error {
+	defer srv.readLock()()
+	return srv.validate(ctx, srv.current())
+}
+
+func (srv *Router) validate(ctx context.Context, st *routerState) error {
+	if srv.opts.CORS != nil {
+		if err := srv.opts.CORS.validate(); err != nil {
+			return err
+		}
+	}
+	if err := srv.checkBindings(); err != nil {
+		return err
+	}
+
+	vErr := &ValidationError{}
+	srv.validateDoc(ctx, st.docRoot, "", vErr)
+	if v := srv.opts.Versioning; v != nil {
+		// the document of the newest version is already validated
+		for _, version := range v.Versions[:len(v.Versions)-1] {
+			vst, err := srv.versionState(st, version.Name)
+			if err != nil {
+				return err
+			}
+			srv.validateDoc(ctx, vst.docRoot, version.Name+" ", vErr)
+		}
+	}
+	if vErr.Err != nil || len(vErr.Issues) > 0 {
+		return vErr
+	}
+	return nil
+}
+
+// validateDoc adds problems of the document to vErr, their locations are prefixed with prefix.
+func (srv *Router) validateDoc(ctx context.Context, doc *openapi3.T, prefix string, vErr *ValidationError) {
+	if err := doc.Validate(ctx); err != nil && vErr.Err == nil {
+		if prefix != "" {
+			err = fmt.Errorf("%s%w", prefix, err)
+		}
+		vErr.Err = err
+	}
+	for _, issue := range Lint(doc) {
+		if issue.Severity == LintError || srv.opts.StrictValidation {
+			issue.Location = prefix + issue.Location
+			vErr.Issues = append(vErr.Issues, issue)
+		}
+	}
+}
+
+// serveCheck keeps result of a check of the router configuration run
+// before serving requests. It's replaced when the checked configuration changes.
+type serveCheck struct {
+	once sync.Once
+	err  error
+}
+
+// serve runs the check once, when it fails the request is answered with 500
+// and the error is logged, so the details aren't leaked to the client.
+func (c *serveCheck) serve(w http.ResponseWriter, check func() error) bool {
+	c.once.Do(func() {
+		if c.err = check(); c.err != nil {
+			log.Printf("docrouter: router misconfigured: %v", c.err)
+		}
+	})
+	if c.err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+// validateOnServe runs Validate before the first request served
+// by the current state, so routes added later are validated too.
+func (srv *Router) validateOnServe(w http.ResponseWriter) bool {
+	st := srv.current()
+	return st.validation.serve(w, func() error {
+		return srv.validate(context.Background(), st)
+	})
+}
@@ -0,0 +1,137 @@
+package docrouter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	noop := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	type StarParameters struct {
+		StarID int `docrouter:"name:starId; kind:path; desc:Star identifier; example:5"`
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		router := New(DefaultOptions)
+		require.NoError(t, router.AddRoute(Route{
+			Path:       "/stars/{starId}",
+			Methods:    []string{http.MethodGet},
+			Parameters: &StarParameters{},
+			Summary:    "Get star",
+			Handler:    noop,
+		}))
+		assert.NoError(t, router.Validate(context.Background()))
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		opts := DefaultOptions
+		opts.StrictValidation = true
+		router := New(opts)
+		require.NoError(t, router.AddRoute(Route{
+			Path:       "/stars/{starId}",
+			Methods:    []string{http.MethodGet},
+			Parameters: &StarParameters{},
+			Summary:    "Get star",
+			Handler:    noop,
+		}))
+
+		err := router.Validate(context.Background())
+		var vErr *ValidationError
+		require.True(t, errors.As(err, &vErr))
+		assert.NoError(t, vErr.Err)
+		assert.Len(t, vErr.Issues, 2)
+	})
+
+	t.Run("parameter without kind", func(t *testing.T) {
+		type Parameters struct {
+			Color string `docrouter:"name:color"`
+		}
+		router := New(DefaultOptions)
+		err := router.AddRoute(Route{
+			Path:       "/stars",
+			Methods:    []string{http.MethodGet},
+			Parameters: &Parameters{},
+			Summary:    "List stars",
+			Handler:    noop,
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `parameter "color" has no kind`)
+	})
+
+	t.Run("duplicate operation id", func(t *testing.T) {
+		router := New(DefaultOptions)
+		for _, path := range []string{"/stars", "/planets"} {
+			require.NoError(t, router.AddRoute(Route{
+				Path:    path,
+				Methods: []string{http.MethodGet},
+				Summary: "List",
+				Handler: noop,
+			}))
+		}
+		err := router.Validate(context.Background())
+		var vErr *ValidationError
+		require.True(t, errors.As(err, &vErr))
+		require.Len(t, vErr.Issues, 1)
+		assert.Equal(t, "duplicate-operation-id", vErr.Issues[0].Rule)
+	})
+
+	t.Run("on serve", func(t *testing.T) {
+		opts := DefaultOptions
+		opts.ValidateOnServe = true
+		router := New(opts)
+		for _, path := range []string{"/stars", "/planets"} {
+			require.NoError(t, router.AddRoute(Route{
+				Path:    path,
+				Methods: []string{http.MethodGet},
+				Summary: "List",
+				Handler: noop,
+			}))
+		}
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stars", nil))
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+		require.NoError(t, router.Replace([]Route{{
+			Path:    "/stars",
+			Methods: []string{http.MethodGet},
+			Summary: "List",
+			Handler: noop,
+		}}))
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stars", nil))
+		assert.Equal(t, http.StatusOK, w.Code, "replaced routes are validated again")
+	})
+
+	t.Run("on serve after adding route", func(t *testing.T) {
+		opts := DefaultOptions
+		opts.ValidateOnServe = true
+		router := New(opts)
+		require.NoError(t, router.AddRoute(Route{
+			Path:    "/stars",
+			Methods: []string{http.MethodGet},
+			Summary: "List",
+			Handler: noop,
+		}))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stars", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		require.NoError(t, router.AddRoute(Route{
+			Path:    "/planets",
+			Methods: []string{http.MethodGet},
+			Summary: "List",
+			Handler: noop,
+		}))
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stars", nil))
+		assert.Equal(t, http.StatusInternalServerError, w.Code, "duplicate operation ID")
+	})
+}
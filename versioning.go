@@ -0,0 +1,295 @@
+package docrouter
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gorilla/mux"
+)
+
+// VersionScheme selects how requests choose API version.
+type VersionScheme int
+
+const (
+	// VersionByPath serves versions under path prefixes, e.g. /v2/stars.
+	VersionByPath VersionScheme = iota
+	// VersionByHeader reads version from Versioning.Header.
+	VersionByHeader
+	// VersionByMediaType reads version from a parameter of Accept header,
+	// e.g. application/json; version=v2.
+	VersionByMediaType
+)
+
+// Versioning serves several versions of the API from one router.
+// Routes belong to versions listed in Route.Versions or to all versions.
+//
+// Router.OpenAPI describes the newest version, documents of the other versions
+// are returned by Router.OpenAPIForVersion.
+type Versioning struct {
+	// Versions from the oldest to the newest
+	Versions []APIVersion
+	Scheme   VersionScheme
+	// Header with the version for VersionByHeader, "API-Version" when empty
+	Header string
+	// MediaTypeParameter with the version for VersionByMediaType, "version" when empty
+	MediaTypeParameter string
+	// Default version of requests which don't ask for one with VersionByHeader
+	// and VersionByMediaType, the newest version when empty.
+	Default string
+}
+
+// APIVersion is a version of the API.
+type APIVersion struct {
+	// Name is used in paths, headers and media types, e.g. "v2"
+	Name string
+	// Deprecation is time since the version is deprecated, zero when it isn't.
+	// Responses of deprecated versions have Deprecation header and their
+	// operations are documented as deprecated.
+	Deprecation time.Time
+	// Sunset is time when the version stops being served, sent in Sunset header
+	Sunset time.Time
+}
+
+func (v *Versioning) validate() error {
+	if len(v.Versions) == 0 {
+		return fmt.Errorf("versioning has no versions")
+	}
+	seen := map[string]bool{}
+	for _, version := range v.Versions {
+		if version.Name == "" || strings.ContainsAny(version.Name, "/{}") {
+			return fmt.Errorf("invalid version name %q", version.Name)
+		}
+		if seen[version.Name] {
+			return fmt.Errorf("version %q is listed multiple times", version.Name)
+		}
+		seen[version.Name] = true
+	}
+	if v.Default != "" && !seen[v.Default] {
+		return fmt.Errorf("default version %q is not listed", v.Default)
+	}
+	return nil
+}
+
+func (v *Versioning) newest() string {
+	return v.Versions[len(v.Versions)-1].Name
+}
+
+func (v *Versioning) defaultVersion() string {
+	if v.Default != "" {
+		return v.Default
+	}
+	return v.newest()
+}
+
+func (v *Versioning) header() string {
+	if v.Header != "" {
+		return v.Header
+	}
+	return "API-Version"
+}
+
+func (v *Versioning) mediaTypeParameter() string {
+	if v.MediaTypeParameter != "" {
+		return v.MediaTypeParameter
+	}
+	return "version"
+}
+
+func (v *Versioning) version(name string) (APIVersion, bool) {
+	for _, version := range v.Versions {
+		if version.Name == name {
+			return version, true
+		}
+	}
+	return APIVersion{}, false
+}
+
+// routeVersions returns names of versions the route belongs to, from the oldest.
+func (v *Versioning) routeVersions(route *Route) []string {
+	names := []string{}
+	for _, version := range v.Versions {
+		if len(route.Versions) == 0 || containsString(route.Versions, version.Name) {
+			names = append(names, version.Name)
+		}
+	}
+	return names
+}
+
+// requested returns version asked for by header or media type request.
+func (v *Versioning) requested(r *http.Request) string {
+	switch v.Scheme {
+	case VersionByHeader:
+		if name := r.Header.Get(v.header()); name != "" {
+			return name
+		}
+	case VersionByMediaType:
+		for _, accept := range splitHeaderList(strings.Join(r.Header.Values("Accept"), ",")) {
+			if _, params, err := mime.ParseMediaType(accept); err == nil && params[v.mediaTypeParameter()] != "" {
+				return params[v.mediaTypeParameter()]
+			}
+		}
+	}
+	return v.defaultVersion()
+}
+
+// versionedPath returns path of the route in the version.
+func (v *Versioning) versionedPath(path, version string) string {
+	if v.Scheme == VersionByPath {
+		return "/" + version + path
+	}
+	return path
+}
+
+// matcher matches requests asking for one of the versions by header or media type.
+func (v *Versioning) matcher(versions []string) mux.MatcherFunc {
+	return func(r *http.Request, _ *mux.RouteMatch) bool {
+		return containsString(versions, v.requested(r))
+	}
+}
+
+// middleware sets headers of the served version and reports use of deprecated versions.
+// The version is fixed for VersionByPath, otherwise it's read from the request.
+func (v *Versioning) middleware(fixed, operationID string, report func(*http.Request, DeprecatedUse)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			name := fixed
+			switch v.Scheme {
+			case VersionByHeader:
+				name = v.requested(r)
+				w.Header().Add("Vary", v.header())
+				w.Header().Set(v.header(), name)
+			case VersionByMediaType:
+				name = v.requested(r)
+				w.Header().Add("Vary", "Accept")
+			}
+			if version, found := v.version(name); found {
+				setDeprecationHeaders(w.Header(), version.Deprecation, version.Sunset)
+				if !version.Deprecation.IsZero() && report != nil {
+					report(r, DeprecatedUse{OperationID: operationID, Version: name})
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (srv *Router) validateRouteVersions(route *Route) error {
+	v := srv.opts.Versioning
+	if v == nil {
+		if len(route.Versions) > 0 {
+			return fmt.Errorf("route has versions, but the router has no versioning")
+		}
+		return nil
+	}
+	if err := v.validate(); err != nil {
+		return err
+	}
+	for _, name := range route.Versions {
+		if _, found := v.version(name); !found {
+			return fmt.Errorf("unknown version %q", name)
+		}
+	}
+	return nil
+}
+
+// addVersionedRouteToDoc adds the route as it's served in the version.
+func (srv *Router) addVersionedRouteToDoc(st *routerState, route Route, version string, params openapi3.Parameters) error {
+	v := srv.opts.Versioning
+	route.Path = v.versionedPath(route.Path, version)
+	if err := st.addRouteToDoc(&route, params); err != nil {
+		return err
+	}
+	if err := srv.addRecoverResponse(st, &route); err != nil {
+		return err
+	}
+
+	docPath, err := openAPIPath(route.Path)
+	if err != nil {
+		return err
+	}
+	apiVersion, _ := v.version(version)
+	for _, method := range route.Methods {
+		operation := st.docRoot.Paths[docPath].GetOperation(method)
+		if !apiVersion.Deprecation.IsZero() {
+			operation.Deprecated = true
+		}
+		switch v.Scheme {
+		case VersionByHeader:
+			// parameters are shared by operations of the route
+			operation.Parameters = append(append(openapi3.Parameters{}, operation.Parameters...), &openapi3.ParameterRef{
+				Value: openapi3.NewHeaderParameter(v.header()).
+					WithDescription("API version").
+					WithRequired(version != v.defaultVersion()).
+					WithSchema(openapi3.NewStringSchema().WithEnum(version)),
+			})
+		case VersionByMediaType:
+			for _, response := range operation.Responses {
+				if response.Ref != "" || response.Value == nil {
+					continue
+				}
+				if mediaType, found := response.Value.Content["application/json"]; found {
+					delete(response.Value.Content, "application/json")
+					response.Value.Content["application/json; "+v.mediaTypeParameter()+"="+version] = mediaType
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// versionState builds state with document of routes in the version.
+// Handlers aren't registered.
+func (srv *Router) versionState(st *routerState, version string) (*routerState, error) {
+	v := srv.opts.Versioning
+	if v == nil {
+		return nil, fmt.Errorf("router has no versioning")
+	}
+	if _, found := v.version(version); !found {
+		return nil, fmt.Errorf("unknown version %q", version)
+	}
+	vst, err := srv.newState()
+	if err != nil {
+		return nil, err
+	}
+	for _, route := range st.added {
+		if containsString(v.routeVersions(&route), version) {
+			params, err := route.openAPI3Params()
+			if err != nil {
+				return nil, fmt.Errorf("create route params: %w", err)
+			}
+			if err := srv.addVersionedRouteToDoc(vst, route, version, params); err != nil {
+				return nil, err
+			}
+		}
+		// keeps indexes of routes for schema components
+		vst.added = append(vst.added, route)
+	}
+	return vst, nil
+}
+
+// OpenAPIForVersion returns OpenAPI document of routes in the version.
+func (srv *Router) OpenAPIForVersion(version string) (*openapi3.T, error) {
+	defer srv.readLock()()
+	vst, err := srv.versionState(srv.current(), version)
+	if err != nil {
+		return nil, err
+	}
+	return vst.docRoot, nil
+}
+
+// OpenAPIJSONForVersion returns OpenAPI document of routes in the version encoded as JSON.
+func (srv *Router) OpenAPIJSONForVersion(version string) ([]byte, error) {
+	defer srv.readLock()()
+	if cached, found := srv.current().versionJSON[version]; found {
+		return append([]byte{}, cached...), nil
+	}
+	vst, err := srv.versionState(srv.current(), version)
+	if err != nil {
+		return nil, err
+	}
+	return vst.marshalDoc(srv.opts.SpecVersion)
+}
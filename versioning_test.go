@@ -0,0 +1,176 @@
+package docrouter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type versioningTestStar struct {
+	Name string `json:"name"`
+}
+
+func TestVersioning(t *testing.T) {
+	deprecation := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	newRouter := func(scheme VersionScheme) *Router {
+		opts := DefaultOptions
+		opts.Versioning = &Versioning{
+			Versions: []APIVersion{
+				{Name: "v1", Deprecation: deprecation, Sunset: sunset},
+				{Name: "v2"},
+			},
+			Scheme:  scheme,
+			Default: "v1",
+		}
+		router := New(opts)
+		handler := func(body string) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, body)
+			})
+		}
+		type params struct {
+			StarID int `docrouter:"name: starId; kind: path; desc: Star identifier; example: 1"`
+		}
+		require.NoError(t, router.AddRoute(Route{
+			Path:         "/stars/{starId}",
+			Methods:      []string{http.MethodGet},
+			Parameters:   &params{},
+			ResponseBody: versioningTestStar{},
+			Summary:      "Get star",
+			OperationID:  "getStar",
+			Handler:      handler("star"),
+		}))
+		require.NoError(t, router.AddRoute(Route{
+			Path:         "/planets",
+			Methods:      []string{http.MethodGet},
+			ResponseBody: []versioningTestStar{},
+			Summary:      "List planets",
+			OperationID:  "listPlanets",
+			Versions:     []string{"v1"},
+			Handler:      handler("planets v1"),
+		}))
+		require.NoError(t, router.AddRoute(Route{
+			Path:         "/planets",
+			Methods:      []string{http.MethodGet},
+			ResponseBody: []versioningTestStar{},
+			Summary:      "List planets",
+			OperationID:  "listPlanetsV2",
+			Versions:     []string{"v2"},
+			Handler:      handler("planets v2"),
+		}))
+		return router
+	}
+	serve := func(router *Router, req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("path", func(t *testing.T) {
+		router := newRouter(VersionByPath)
+
+		rec := serve(router, httptest.NewRequest(http.MethodGet, "/v1/planets", nil))
+		assert.Equal(t, "planets v1", rec.Body.String())
+		assert.Equal(t, "@1767225600", rec.Header().Get("Deprecation"))
+		assert.Equal(t, "Fri, 01 Jan 2027 00:00:00 GMT", rec.Header().Get("Sunset"))
+
+		rec = serve(router, httptest.NewRequest(http.MethodGet, "/v2/planets", nil))
+		assert.Equal(t, "planets v2", rec.Body.String())
+		assert.Empty(t, rec.Header().Get("Deprecation"))
+
+		assert.Equal(t, "star", serve(router, httptest.NewRequest(http.MethodGet, "/v1/stars/1", nil)).Body.String())
+		assert.Equal(t, http.StatusNotFound, serve(router, httptest.NewRequest(http.MethodGet, "/planets", nil)).Code)
+
+		doc := router.OpenAPI()
+		assert.Contains(t, doc.Paths, "/v2/planets")
+		assert.Contains(t, doc.Paths, "/v2/stars/{starId}")
+		assert.NotContains(t, doc.Paths, "/v1/planets", "the main document describes the newest version")
+
+		v1, err := router.OpenAPIForVersion("v1")
+		require.NoError(t, err)
+		assert.Len(t, v1.Paths, 2)
+		assert.Equal(t, "listPlanets", v1.Paths["/v1/planets"].Get.OperationID)
+		assert.True(t, v1.Paths["/v1/stars/{starId}"].Get.Deprecated)
+
+		_, err = router.OpenAPIJSONForVersion("v3")
+		assert.EqualError(t, err, `unknown version "v3"`)
+
+		u, err := router.URL("getStar", &struct {
+			StarID int `docrouter:"name: starId; kind: path"`
+		}{StarID: 3})
+		require.NoError(t, err)
+		assert.Equal(t, "/v2/stars/3", u.String())
+
+		routes := router.Routes()
+		assert.Equal(t, []string{"v1", "v2"}, routes[0].Versions)
+		assert.Equal(t, []string{"v1"}, routes[1].Versions)
+
+		assert.NoError(t, router.Validate(context.Background()))
+	})
+
+	t.Run("header", func(t *testing.T) {
+		router := newRouter(VersionByHeader)
+
+		req := httptest.NewRequest(http.MethodGet, "/planets", nil)
+		req.Header.Set("API-Version", "v2")
+		rec := serve(router, req)
+		assert.Equal(t, "planets v2", rec.Body.String())
+		assert.Equal(t, "v2", rec.Header().Get("API-Version"))
+		assert.Equal(t, "API-Version", rec.Header().Get("Vary"))
+
+		rec = serve(router, httptest.NewRequest(http.MethodGet, "/planets", nil))
+		assert.Equal(t, "planets v1", rec.Body.String(), "default version")
+		assert.NotEmpty(t, rec.Header().Get("Deprecation"))
+
+		req = httptest.NewRequest(http.MethodGet, "/planets", nil)
+		req.Header.Set("API-Version", "v3")
+		assert.Equal(t, http.StatusNotFound, serve(router, req).Code)
+
+		param := router.OpenAPI().Paths["/planets"].Get.Parameters.GetByInAndName("header", "API-Version")
+		require.NotNil(t, param)
+		assert.True(t, param.Required, "v2 isn't the default version")
+		assert.Equal(t, []interface{}{"v2"}, param.Schema.Value.Enum)
+
+		assert.NoError(t, router.Validate(context.Background()))
+	})
+
+	t.Run("media type", func(t *testing.T) {
+		router := newRouter(VersionByMediaType)
+
+		req := httptest.NewRequest(http.MethodGet, "/planets", nil)
+		req.Header.Set("Accept", "text/html, application/json; version=v2")
+		assert.Equal(t, "planets v2", serve(router, req).Body.String())
+		assert.Equal(t, "planets v1", serve(router, httptest.NewRequest(http.MethodGet, "/planets", nil)).Body.String())
+
+		content := router.OpenAPI().Paths["/planets"].Get.Responses["200"].Value.Content
+		assert.Contains(t, content, "application/json; version=v2")
+	})
+
+	t.Run("invalid versions", func(t *testing.T) {
+		router := newRouter(VersionByPath)
+		err := router.AddRoute(Route{
+			Path:     "/moons",
+			Methods:  []string{http.MethodGet},
+			Summary:  "List moons",
+			Versions: []string{"v3"},
+			Handler:  http.NotFoundHandler(),
+		})
+		assert.EqualError(t, err, `route validation: unknown version "v3"`)
+
+		err = New(DefaultOptions).AddRoute(Route{
+			Path:     "/moons",
+			Methods:  []string{http.MethodGet},
+			Summary:  "List moons",
+			Versions: []string{"v1"},
+			Handler:  http.NotFoundHandler(),
+		})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,72 @@
+package docrouter
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// AddWebhook documents a webhook, a request the API sends to its consumers.
+// Methods (POST by default), RequestBody, ResponseBody, Responses, Summary,
+// Description, OperationID, Tags and Extensions of the route are used, the rest is ignored.
+//
+// Webhooks are written to the webhooks field of OpenAPI 3.1 documents
+// and to the x-webhooks extension of OpenAPI 3.0 documents.
+func (srv *Router) AddWebhook(name string, route Route) error {
+	if len(route.Methods) == 0 {
+		route.Methods = []string{http.MethodPost}
+	}
+	return srv.modifyComponents(name, func(st *routerState) error {
+		return st.addWebhook(name, &route)
+	}, func() {
+		srv.webhooks = append(srv.webhooks, namedWebhook{name: name, route: route})
+	})
+}
+
+// namedWebhook is a webhook registered with AddWebhook.
+type namedWebhook struct {
+	name  string
+	route Route
+}
+
+func (st *routerState) addWebhook(name string, route *Route) error {
+	if st.docRoot.Extensions == nil {
+		st.docRoot.Extensions = map[string]interface{}{}
+	}
+	webhooks, _ := st.docRoot.Extensions[webhooksExtension].(map[string]*openapi3.PathItem)
+	if webhooks == nil {
+		webhooks = map[string]*openapi3.PathItem{}
+	}
+	if _, found := webhooks[name]; found {
+		return fmt.Errorf("webhook %q is already registered", name)
+	}
+
+	// webhooks count as separate routes for promoting schemas to components
+	st.schemas.forRoute(-1 - len(webhooks))
+	requestBody := st.operationRequestBody(route)
+	responses, err := st.operationResponses(route)
+	if err != nil {
+		return err
+	}
+	item := &openapi3.PathItem{}
+	methods := append([]string{}, route.Methods...)
+	sort.Strings(methods)
+	for _, method := range methods {
+		item.SetOperation(method, &openapi3.Operation{
+			Summary:     route.Summary,
+			Description: route.Description,
+			OperationID: route.OperationID,
+			Tags:        route.Tags,
+			RequestBody: requestBody,
+			Responses:   responses,
+			ExtensionProps: openapi3.ExtensionProps{
+				Extensions: copyExtensions(route.Extensions),
+			},
+		})
+	}
+	webhooks[name] = item
+	st.docRoot.Extensions[webhooksExtension] = webhooks
+	return nil
+}